@@ -297,11 +297,12 @@ func (c *client) GetMachineDeploymentsForCluster(cluster *clusterv1.Cluster) ([]
 		return nil, errors.Wrapf(err, "error listing MachineDeployments for Cluster %s/%s", cluster.Namespace, cluster.Name)
 	}
 	var machineDeployments []*clusterv1.MachineDeployment
-	for _, md := range machineDeploymentList.Items {
+	for i := range machineDeploymentList.Items {
+		md := &machineDeploymentList.Items[i]
 		for _, or := range md.GetOwnerReferences() {
 			if or.Kind == cluster.Kind && or.Name == cluster.Name {
-				machineDeployments = append(machineDeployments, &md)
-				continue
+				machineDeployments = append(machineDeployments, md)
+				break
 			}
 		}
 	}
@@ -348,11 +349,12 @@ func (c *client) GetMachineSetsForCluster(cluster *clusterv1.Cluster) ([]*cluste
 		return nil, errors.Wrapf(err, "error listing MachineSets for Cluster %s/%s", cluster.Namespace, cluster.Name)
 	}
 	var machineSets []*clusterv1.MachineSet
-	for _, ms := range machineSetList.Items {
+	for i := range machineSetList.Items {
+		ms := &machineSetList.Items[i]
 		for _, or := range ms.GetOwnerReferences() {
 			if or.Kind == cluster.Kind && or.Name == cluster.Name {
-				machineSets = append(machineSets, &ms)
-				continue
+				machineSets = append(machineSets, ms)
+				break
 			}
 		}
 	}
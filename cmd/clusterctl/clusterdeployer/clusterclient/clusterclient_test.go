@@ -16,6 +16,254 @@ limitations under the License.
 
 package clusterclient
 
-// TODO: Test clusterclient. To do this properly, etcd and kubectl need to be on the box running the test.
-// Placeholder till the presubmit images have the needed binaries.
-// https://github.com/kubernetes-sigs/cluster-api/issues/254
+import (
+	stdlog "log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	tcmd "k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	clusterv1 "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+// The tests run against a real API server serving the Cluster API CRDs, started by envtest.
+// Apply and Delete shell out to kubectl and aren't covered.
+
+var (
+	cfg        *rest.Config
+	kubeconfig string
+)
+
+func TestMain(m *testing.M) {
+	t := &envtest.Environment{
+		CRDDirectoryPaths: []string{filepath.Join("..", "..", "..", "..", "config", "crds")},
+	}
+
+	var err error
+	if cfg, err = t.Start(); err != nil {
+		stdlog.Fatal(err)
+	}
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters["envtest"] = &clientcmdapi.Cluster{Server: cfg.Host}
+	config.AuthInfos["envtest"] = &clientcmdapi.AuthInfo{}
+	config.Contexts["envtest"] = &clientcmdapi.Context{Cluster: "envtest", AuthInfo: "envtest"}
+	config.CurrentContext = "envtest"
+	data, err := tcmd.Write(*config)
+	if err != nil {
+		stdlog.Fatal(err)
+	}
+	kubeconfig = string(data)
+
+	code := m.Run()
+	t.Stop()
+	os.Exit(code)
+}
+
+// newTestClient returns a client of the test API server and a new namespace for the test.
+func newTestClient(t *testing.T) (*client, string) {
+	t.Helper()
+
+	c, err := New(kubeconfig)
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+
+	ns, err := kubernetes.NewForConfigOrDie(cfg).CoreV1().Namespaces().Create(&apiv1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "clusterclient-"},
+	})
+	if err != nil {
+		t.Fatalf("error creating namespace: %v", err)
+	}
+	return c, ns.Name
+}
+
+func newTestCluster(namespace, name string) *clusterv1.Cluster {
+	return &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: clusterv1.ClusterSpec{
+			ClusterNetwork: clusterv1.ClusterNetworkingConfig{
+				Services:      clusterv1.NetworkRanges{CIDRBlocks: []string{"10.96.0.0/12"}},
+				Pods:          clusterv1.NetworkRanges{CIDRBlocks: []string{"192.168.0.0/16"}},
+				ServiceDomain: "cluster.local",
+			},
+		},
+	}
+}
+
+func TestWaitForClusterV1alpha1Ready(t *testing.T) {
+	c, _ := newTestClient(t)
+	defer c.Close()
+
+	if err := c.WaitForClusterV1alpha1Ready(); err != nil {
+		t.Fatalf("expected Cluster API to be ready, got %v", err)
+	}
+}
+
+func TestEnsureAndDeleteNamespace(t *testing.T) {
+	c, _ := newTestClient(t)
+	defer c.Close()
+	namespaces := kubernetes.NewForConfigOrDie(cfg).CoreV1().Namespaces()
+
+	for i := 0; i < 2; i++ {
+		if err := c.EnsureNamespace("ensured"); err != nil {
+			t.Fatalf("error ensuring namespace: %v", err)
+		}
+	}
+	if _, err := namespaces.Get("ensured", metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected namespace to exist, got %v", err)
+	}
+
+	if err := c.DeleteNamespace("ensured"); err != nil {
+		t.Fatalf("error deleting namespace: %v", err)
+	}
+	// envtest runs no namespace controller, so the namespace stays terminating.
+	ns, err := namespaces.Get("ensured", metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		t.Fatalf("error getting namespace: %v", err)
+	}
+	if err == nil && ns.DeletionTimestamp == nil {
+		t.Fatal("expected namespace to be deleted")
+	}
+
+	if err := c.DeleteNamespace(apiv1.NamespaceDefault); err != nil {
+		t.Fatalf("expected deleting the default namespace to be skipped, got %v", err)
+	}
+	if _, err := namespaces.Get(apiv1.NamespaceDefault, metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected default namespace to exist, got %v", err)
+	}
+}
+
+func TestClusterObject(t *testing.T) {
+	c, namespace := newTestClient(t)
+	defer c.Close()
+
+	cluster := newTestCluster(namespace, "foo")
+	cluster.Finalizers = []string{clusterv1.ClusterFinalizer}
+	if err := c.CreateClusterObject(cluster); err != nil {
+		t.Fatalf("error creating cluster: %v", err)
+	}
+
+	if err := c.UpdateClusterObjectEndpoint("10.0.0.1", "foo", namespace); err != nil {
+		t.Fatalf("error updating cluster endpoint: %v", err)
+	}
+	if err := c.UpdateClusterObjectEndpoint("10.0.0.2:6443", "foo", namespace); err != nil {
+		t.Fatalf("error updating cluster endpoint: %v", err)
+	}
+
+	got, err := c.GetCluster("foo", namespace)
+	if err != nil {
+		t.Fatalf("error getting cluster: %v", err)
+	}
+	expected := []clusterv1.APIEndpoint{{Host: "10.0.0.1", Port: 443}, {Host: "10.0.0.2", Port: 6443}}
+	if len(got.Status.APIEndpoints) != 2 || got.Status.APIEndpoints[0] != expected[0] || got.Status.APIEndpoints[1] != expected[1] {
+		t.Fatalf("expected API endpoints %v, got %v", expected, got.Status.APIEndpoints)
+	}
+
+	if err := c.ForceDeleteCluster(namespace, "foo"); err != nil {
+		t.Fatalf("error force deleting cluster: %v", err)
+	}
+	clusters, err := c.GetClusters(namespace)
+	if err != nil {
+		t.Fatalf("error listing clusters: %v", err)
+	}
+	if len(clusters) != 0 {
+		t.Fatalf("expected cluster with finalizer to be deleted, got %v", clusters)
+	}
+}
+
+func TestGetObjectsForCluster(t *testing.T) {
+	c, namespace := newTestClient(t)
+	defer c.Close()
+
+	if err := c.CreateClusterObject(newTestCluster(namespace, "foo")); err != nil {
+		t.Fatalf("error creating cluster: %v", err)
+	}
+	cluster, err := c.GetCluster("foo", namespace)
+	if err != nil {
+		t.Fatalf("error getting cluster: %v", err)
+	}
+	cluster.Kind = "Cluster"
+
+	labels := map[string]string{machineClusterLabelName: "foo"}
+	owner := []metav1.OwnerReference{*metav1.NewControllerRef(cluster, clusterv1.SchemeGroupVersion.WithKind("Cluster"))}
+	selector := metav1.LabelSelector{MatchLabels: map[string]string{"set": "foo"}}
+	template := clusterv1.MachineTemplateSpec{ObjectMeta: clusterv1.ObjectMeta{Labels: map[string]string{"set": "foo"}}}
+
+	deployments := []*clusterv1.MachineDeployment{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "owned", Labels: labels, OwnerReferences: owner},
+			Spec:       clusterv1.MachineDeploymentSpec{Selector: selector, Template: template},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "not-owned", Labels: labels},
+			Spec:       clusterv1.MachineDeploymentSpec{Selector: selector, Template: template},
+		},
+	}
+	if err := c.CreateMachineDeployments(deployments, namespace); err != nil {
+		t.Fatalf("error creating machine deployments: %v", err)
+	}
+
+	machineSets := []*clusterv1.MachineSet{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "owned", Labels: labels, OwnerReferences: owner},
+			Spec:       clusterv1.MachineSetSpec{Selector: selector, Template: template},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-cluster", OwnerReferences: owner},
+			Spec:       clusterv1.MachineSetSpec{Selector: selector, Template: template},
+		},
+	}
+	if err := c.CreateMachineSets(machineSets, namespace); err != nil {
+		t.Fatalf("error creating machine sets: %v", err)
+	}
+
+	// Machines with annotations are considered ready by CreateMachines.
+	annotations := map[string]string{"ready": "true"}
+	machines := []*clusterv1.Machine{
+		{ObjectMeta: metav1.ObjectMeta{Name: "owned", Labels: labels, Annotations: annotations, OwnerReferences: owner}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "not-owned", Labels: labels, Annotations: annotations}},
+	}
+	if err := c.CreateMachines(machines, namespace); err != nil {
+		t.Fatalf("error creating machines: %v", err)
+	}
+
+	gotDeployments, err := c.GetMachineDeploymentsForCluster(cluster)
+	if err != nil {
+		t.Fatalf("error getting machine deployments: %v", err)
+	}
+	if len(gotDeployments) != 1 || gotDeployments[0].Name != "owned" {
+		t.Fatalf("expected the owned machine deployment, got %v", gotDeployments)
+	}
+
+	gotMachineSets, err := c.GetMachineSetsForCluster(cluster)
+	if err != nil {
+		t.Fatalf("error getting machine sets: %v", err)
+	}
+	if len(gotMachineSets) != 1 || gotMachineSets[0].Name != "owned" {
+		t.Fatalf("expected the owned and labeled machine set, got %v", gotMachineSets)
+	}
+
+	gotMachines, err := c.GetMachinesForCluster(cluster)
+	if err != nil {
+		t.Fatalf("error getting machines: %v", err)
+	}
+	if len(gotMachines) != 1 || gotMachines[0].Name != "owned" {
+		t.Fatalf("expected the owned machine, got %v", gotMachines)
+	}
+
+	allMachines, err := c.GetMachines(namespace)
+	if err != nil {
+		t.Fatalf("error listing machines: %v", err)
+	}
+	if len(allMachines) != 2 {
+		t.Fatalf("expected 2 machines, got %d", len(allMachines))
+	}
+}
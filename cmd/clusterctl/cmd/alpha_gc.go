@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/gc"
+	"sigs.k8s.io/cluster-api/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+type AlphaGCOptions struct {
+	Namespace string
+	Delete    bool
+}
+
+var gco = &AlphaGCOptions{}
+
+var alphaGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Find Cluster API objects whose owner is gone",
+	Long: `Find Cluster API objects with an owner reference to an object which does not exist anymore,
+MachineDeployments, MachineSets and Machines of deleted Clusters, and secrets owned by or
+labelled with the name of deleted Clusters. They are only deleted with --delete.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := RunAlphaGC(gco); err != nil {
+			os.Stdout.Sync()
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func RunAlphaGC(gco *AlphaGCOptions) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to create client configuration")
+	}
+	mgr, err := manager.New(cfg, manager.Options{})
+	if err != nil {
+		return errors.Wrap(err, "failed to create manager")
+	}
+	if err := apis.AddToScheme(mgr.GetScheme()); err != nil {
+		return errors.Wrap(err, "failed to add APIs to manager")
+	}
+
+	c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return errors.Wrap(err, "failed to create client")
+	}
+
+	orphans, err := gc.FindOrphans(context.TODO(), c, gco.Namespace)
+	if err != nil {
+		return err
+	}
+	if len(orphans) == 0 {
+		fmt.Fprintln(os.Stdout, "No orphaned objects found")
+		return nil
+	}
+	for _, o := range orphans {
+		fmt.Fprintln(os.Stdout, o)
+	}
+
+	if !gco.Delete {
+		return nil
+	}
+	return gc.DeleteOrphans(context.TODO(), os.Stdout, c, orphans)
+}
+
+func init() {
+	alphaGCCmd.Flags().StringVarP(&gco.Namespace, "namespace", "n", "", "Namespace to look for orphaned objects in, defaults to all namespaces")
+	alphaGCCmd.Flags().BoolVarP(&gco.Delete, "delete", "", false, "Delete the orphaned objects found")
+	alphaCmd.AddCommand(alphaGCCmd)
+}
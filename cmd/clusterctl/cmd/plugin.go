@@ -0,0 +1,156 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// pluginPrefix is the prefix of the name of the executables run as clusterctl plugins.
+	pluginPrefix = "clusterctl-"
+
+	// pluginBinaryEnv is set for plugins to the path of the clusterctl binary that runs them.
+	pluginBinaryEnv = "CLUSTERCTL_BINARY"
+)
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Provides utilities for interacting with plugins",
+	Long: `Provides utilities for interacting with plugins.
+
+Plugins are executables named clusterctl-<name> found in the PATH, which extend clusterctl
+with the "clusterctl <name>" command. Dashes in the name of the executable add subcommands,
+e.g. clusterctl-foo-bar is run by "clusterctl foo bar". Plugins get the remaining arguments,
+inherit the environment, and get the path of clusterctl in $CLUSTERCTL_BINARY. They cannot
+override the commands built into clusterctl.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all the plugins found in the PATH",
+	Long:  `List all the plugins found in the PATH, along with the ones which are shadowed or cannot be run.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !listPlugins(os.Stdout, os.Stderr, filepath.SplitList(os.Getenv("PATH"))) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginListCmd)
+	RootCmd.AddCommand(pluginCmd)
+}
+
+// listPlugins writes the plugins found in dirs to w, and the problems with them to errw.
+// It returns false if no plugin was found or some have problems.
+func listPlugins(w, errw io.Writer, dirs []string) bool {
+	seen := map[string]string{}
+	ok := true
+	for _, dir := range dirs {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, f := range files {
+			if f.IsDir() || !strings.HasPrefix(f.Name(), pluginPrefix) {
+				continue
+			}
+			path := filepath.Join(dir, f.Name())
+			fmt.Fprintln(w, path)
+
+			name := strings.TrimSuffix(f.Name(), filepath.Ext(f.Name()))
+			if f.Mode()&0111 == 0 {
+				fmt.Fprintf(errw, "  - warning: %s is not executable\n", path)
+				ok = false
+			}
+			if first, found := seen[name]; found {
+				fmt.Fprintf(errw, "  - warning: %s is shadowed by %s\n", path, first)
+				ok = false
+			} else {
+				seen[name] = path
+			}
+			if args := strings.Split(strings.TrimPrefix(name, pluginPrefix), "-"); isBuiltinCommand(args) {
+				fmt.Fprintf(errw, "  - warning: %s overrides the %q command, which is built into clusterctl\n", path, strings.Join(args, " "))
+				ok = false
+			}
+		}
+	}
+	if len(seen) == 0 {
+		fmt.Fprintln(errw, "error: unable to find any clusterctl plugins in the PATH")
+		return false
+	}
+	return ok
+}
+
+// isBuiltinCommand returns true if the arguments are handled by a command built into clusterctl.
+func isBuiltinCommand(args []string) bool {
+	cmd, _, err := RootCmd.Find(args)
+	return err == nil && cmd != RootCmd
+}
+
+// lookupPlugin returns the path of the plugin run by the arguments, along with the
+// arguments left for the plugin, picking the plugin with the most subcommands.
+func lookupPlugin(args []string, lookPath func(string) (string, error)) (string, []string, bool) {
+	var names []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		names = append(names, strings.Replace(arg, "-", "_", -1))
+	}
+	for i := len(names); i > 0; i-- {
+		if path, err := lookPath(pluginPrefix + strings.Join(names[:i], "-")); err == nil {
+			return path, args[i:], true
+		}
+	}
+	return "", nil, false
+}
+
+// runPlugin runs the plugin handling the arguments of clusterctl if there is one and they
+// are not handled by a built-in command. It returns false if no plugin was run.
+func runPlugin(args []string) (bool, error) {
+	if len(args) == 0 || isBuiltinCommand(args) {
+		return false, nil
+	}
+	path, pluginArgs, found := lookupPlugin(args, exec.LookPath)
+	if !found {
+		return false, nil
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		self = os.Args[0]
+	}
+	cmd := exec.Command(path, pluginArgs...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), pluginBinaryEnv+"="+self)
+	return true, cmd.Run()
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLookupPlugin(t *testing.T) {
+	plugins := map[string]bool{
+		"clusterctl-foo":         true,
+		"clusterctl-foo-bar":     true,
+		"clusterctl-with_dashes": true,
+	}
+	lookPath := func(name string) (string, error) {
+		if plugins[name] {
+			return "/bin/" + name, nil
+		}
+		return "", errors.New("not found")
+	}
+
+	var testcases = []struct {
+		name       string
+		args       []string
+		path       string
+		pluginArgs []string
+	}{
+		{
+			name:       "plugin",
+			args:       []string{"foo", "baz"},
+			path:       "/bin/clusterctl-foo",
+			pluginArgs: []string{"baz"},
+		},
+		{
+			name:       "plugin with subcommand",
+			args:       []string{"foo", "bar", "--flag", "value"},
+			path:       "/bin/clusterctl-foo-bar",
+			pluginArgs: []string{"--flag", "value"},
+		},
+		{
+			name:       "subcommands stop at the first flag",
+			args:       []string{"foo", "--flag", "bar"},
+			path:       "/bin/clusterctl-foo",
+			pluginArgs: []string{"--flag", "bar"},
+		},
+		{
+			name: "dashes in the command",
+			args: []string{"with-dashes"},
+			path: "/bin/clusterctl-with_dashes",
+		},
+		{
+			name: "no plugin",
+			args: []string{"baz"},
+		},
+		{
+			name: "flags only",
+			args: []string{"--foo"},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			path, pluginArgs, found := lookupPlugin(tc.args, lookPath)
+			if found != (tc.path != "") {
+				t.Fatalf("expected plugin found: %v, got %v", tc.path != "", found)
+			}
+			if path != tc.path {
+				t.Errorf("expected plugin %q, got %q", tc.path, path)
+			}
+			if len(pluginArgs) != 0 || len(tc.pluginArgs) != 0 {
+				if !reflect.DeepEqual(pluginArgs, tc.pluginArgs) {
+					t.Errorf("expected plugin arguments %v, got %v", tc.pluginArgs, pluginArgs)
+				}
+			}
+		})
+	}
+}
+
+func TestListPlugins(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clusterctl-plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(path string, mode os.FileMode) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, nil, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first := filepath.Join(dir, "first")
+	second := filepath.Join(dir, "second")
+	write(filepath.Join(first, "clusterctl-foo"), 0755)
+	write(filepath.Join(first, "kubectl-foo"), 0755)
+	write(filepath.Join(second, "clusterctl-foo"), 0755)
+	write(filepath.Join(second, "clusterctl-bar"), 0644)
+	write(filepath.Join(second, "clusterctl-create-cluster"), 0755)
+
+	var out, errOut bytes.Buffer
+	if listPlugins(&out, &errOut, []string{first, second, filepath.Join(dir, "missing")}) {
+		t.Error("expected problems to be reported")
+	}
+	expected := []string{
+		filepath.Join(first, "clusterctl-foo"),
+		filepath.Join(second, "clusterctl-bar"),
+		filepath.Join(second, "clusterctl-create-cluster"),
+		filepath.Join(second, "clusterctl-foo"),
+	}
+	if got := strings.Fields(out.String()); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected plugins %v, got %v", expected, got)
+	}
+	for _, warning := range []string{
+		filepath.Join(second, "clusterctl-bar") + " is not executable",
+		filepath.Join(second, "clusterctl-foo") + " is shadowed by " + filepath.Join(first, "clusterctl-foo"),
+		`overrides the "create cluster" command`,
+	} {
+		if !strings.Contains(errOut.String(), warning) {
+			t.Errorf("expected warning %q, got:\n%s", warning, errOut.String())
+		}
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if !listPlugins(&out, &errOut, []string{first}) {
+		t.Errorf("expected no problems, got:\n%s", errOut.String())
+	}
+	if listPlugins(&out, &errOut, []string{filepath.Join(dir, "missing")}) {
+		t.Error("expected an error when no plugin is found")
+	}
+}
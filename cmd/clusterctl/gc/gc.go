@@ -0,0 +1,191 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gc finds the Cluster API objects, and the secrets of Clusters, left behind once
+// the objects they belong to are gone.
+package gc
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Orphan is an object whose owner is gone.
+type Orphan struct {
+	Kind      string
+	Namespace string
+	Name      string
+
+	// Reason describes which owner is gone.
+	Reason string
+
+	obj runtime.Object
+}
+
+func (o Orphan) String() string {
+	return fmt.Sprintf("%s %s/%s: %s", o.Kind, o.Namespace, o.Name, o.Reason)
+}
+
+// listedKinds are the kinds of the Cluster API objects listed by FindOrphans. Owner references
+// to other kinds, like MachineClasses, are not checked.
+var listedKinds = map[string]bool{
+	"Cluster":                   true,
+	"MachineDeployment":         true,
+	"MachineSet":                true,
+	"Machine":                   true,
+	"ClusterResourceSetBinding": true,
+}
+
+// object is an object listed from the API server along with its kind.
+type object struct {
+	kind string
+	meta metav1.Object
+	obj  runtime.Object
+}
+
+// FindOrphans returns the objects in namespace, or in all namespaces if namespace is empty,
+// which are either:
+//   - MachineDeployments, MachineSets, Machines, ClusterResourceSetBindings or Secrets
+//     with an owner reference to a Cluster API object which does not exist anymore.
+//   - MachineDeployments, MachineSets or Machines labelled with the name of a Cluster
+//     which does not exist anymore.
+//   - Secrets owned by or labelled with the name of a Cluster which does not exist anymore,
+//     like its kubeconfig or certificate authority, in namespaces holding Cluster API objects.
+//
+// Only owner references to the kinds listed above are checked. Objects being deleted are ignored.
+func FindOrphans(ctx context.Context, c client.Client, namespace string) ([]Orphan, error) {
+	clusters := &clusterv1.ClusterList{}
+	deployments := &clusterv1.MachineDeploymentList{}
+	machineSets := &clusterv1.MachineSetList{}
+	machines := &clusterv1.MachineList{}
+	bindings := &clusterv1.ClusterResourceSetBindingList{}
+	secrets := &corev1.SecretList{}
+	for _, list := range []runtime.Object{clusters, deployments, machineSets, machines, bindings, secrets} {
+		if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			return nil, errors.Wrapf(err, "failed to list %T in namespace %q", list, namespace)
+		}
+	}
+
+	var objs []object
+	for i := range clusters.Items {
+		objs = append(objs, object{"Cluster", &clusters.Items[i], &clusters.Items[i]})
+	}
+	for i := range deployments.Items {
+		objs = append(objs, object{"MachineDeployment", &deployments.Items[i], &deployments.Items[i]})
+	}
+	for i := range machineSets.Items {
+		objs = append(objs, object{"MachineSet", &machineSets.Items[i], &machineSets.Items[i]})
+	}
+	for i := range machines.Items {
+		objs = append(objs, object{"Machine", &machines.Items[i], &machines.Items[i]})
+	}
+	for i := range bindings.Items {
+		objs = append(objs, object{"ClusterResourceSetBinding", &bindings.Items[i], &bindings.Items[i]})
+	}
+
+	// The UIDs of the Cluster API objects, by kind, namespace and name.
+	uids := map[string]types.UID{}
+	namespaces := map[string]bool{}
+	for _, o := range objs {
+		uids[key(o.kind, o.meta.GetNamespace(), o.meta.GetName())] = o.meta.GetUID()
+		namespaces[o.meta.GetNamespace()] = true
+	}
+
+	for i := range secrets.Items {
+		if namespaces[secrets.Items[i].Namespace] && isClusterSecret(&secrets.Items[i]) {
+			objs = append(objs, object{"Secret", &secrets.Items[i], &secrets.Items[i]})
+		}
+	}
+
+	var orphans []Orphan
+	for _, o := range objs {
+		if o.kind == "Cluster" || o.meta.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if reason := orphanReason(o, uids); reason != "" {
+			orphans = append(orphans, Orphan{
+				Kind:      o.kind,
+				Namespace: o.meta.GetNamespace(),
+				Name:      o.meta.GetName(),
+				Reason:    reason,
+				obj:       o.obj,
+			})
+		}
+	}
+	return orphans, nil
+}
+
+// orphanReason returns why the object is an orphan, or an empty string if it is not.
+func orphanReason(o object, uids map[string]types.UID) string {
+	namespace := o.meta.GetNamespace()
+
+	for _, ref := range o.meta.GetOwnerReferences() {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil || gv.Group != clusterv1.SchemeGroupVersion.Group || !listedKinds[ref.Kind] {
+			continue
+		}
+		if uid, ok := uids[key(ref.Kind, namespace, ref.Name)]; !ok || uid != ref.UID {
+			return fmt.Sprintf("owner %s %q not found", ref.Kind, ref.Name)
+		}
+	}
+
+	if cluster, ok := o.meta.GetLabels()[clusterv1.MachineClusterLabelName]; ok && o.kind != "ClusterResourceSetBinding" {
+		if _, exists := uids[key("Cluster", namespace, cluster)]; !exists {
+			return fmt.Sprintf("Cluster %q not found", cluster)
+		}
+	}
+	return ""
+}
+
+// isClusterSecret returns true if the secret is owned by or labelled with the name of a Cluster.
+func isClusterSecret(secret *corev1.Secret) bool {
+	if _, ok := secret.Labels[clusterv1.MachineClusterLabelName]; ok {
+		return true
+	}
+	for _, ref := range secret.OwnerReferences {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err == nil && gv.Group == clusterv1.SchemeGroupVersion.Group && ref.Kind == "Cluster" {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteOrphans deletes the orphans, letting the garbage collector delete their dependents.
+func DeleteOrphans(ctx context.Context, w io.Writer, c client.Client, orphans []Orphan) error {
+	for _, o := range orphans {
+		if err := c.Delete(ctx, o.obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete %s %q in namespace %q", o.Kind, o.Name, o.Namespace)
+		}
+		fmt.Fprintf(w, "Deleted %s %s/%s\n", o.Kind, o.Namespace, o.Name)
+	}
+	return nil
+}
+
+func key(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gc
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	clusterv1 "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestFindOrphans(t *testing.T) {
+	clusterv1.AddToScheme(scheme.Scheme)
+	now := metav1.Now()
+
+	ownerRef := func(kind, name, uid string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{APIVersion: clusterv1.SchemeGroupVersion.String(), Kind: kind, Name: name, UID: k8stypes.UID(uid)}}
+	}
+	labels := func(cluster string) map[string]string {
+		return map[string]string{clusterv1.MachineClusterLabelName: cluster}
+	}
+
+	objs := []runtime.Object{
+		&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", UID: "foo-uid"}},
+		&clusterv1.MachineSet{ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "default", UID: "ms-uid", Labels: labels("foo"), OwnerReferences: ownerRef("Cluster", "foo", "foo-uid")}},
+		&clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "m", Namespace: "default", Labels: labels("foo"), OwnerReferences: ownerRef("MachineSet", "ms", "ms-uid")}},
+
+		// Owned by a MachineDeployment which is gone.
+		&clusterv1.MachineSet{ObjectMeta: metav1.ObjectMeta{Name: "ms-no-md", Namespace: "default", Labels: labels("foo"), OwnerReferences: ownerRef("MachineDeployment", "md", "md-uid")}},
+		// Owned by a Cluster which was recreated with the same name.
+		&clusterv1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Name: "md-old-cluster", Namespace: "default", OwnerReferences: ownerRef("Cluster", "foo", "old-uid")}},
+		// Labelled with a Cluster which is gone.
+		&clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "m-no-cluster", Namespace: "default", Labels: labels("bar")}},
+		// Labelled with a Cluster in another namespace.
+		&clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "m-other-namespace", Namespace: "other", Labels: labels("foo")}},
+		// Being deleted.
+		&clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "m-deleted", Namespace: "default", Labels: labels("bar"), DeletionTimestamp: &now}},
+		// Bound to a Cluster which is gone.
+		&clusterv1.ClusterResourceSetBinding{ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: "default", OwnerReferences: ownerRef("Cluster", "bar", "bar-uid")}},
+
+		// Owned by a MachineClass, which is not checked.
+		&clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "m-class", Namespace: "default", Labels: labels("foo"), OwnerReferences: ownerRef("MachineClass", "class", "class-uid")}},
+
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "foo-kubeconfig", Namespace: "default", Labels: labels("foo")}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "bar-kubeconfig", Namespace: "default", Labels: labels("bar")}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "bar-ca", Namespace: "default", OwnerReferences: ownerRef("Cluster", "bar", "bar-uid")}},
+		// Neither owned by nor labelled with a Cluster, like the CA of cert-manager.
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "selfsigned-ca", Namespace: "default"}, Data: map[string][]byte{"tls.crt": nil, "tls.key": nil}},
+		// Owned by something else than a Cluster API object.
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "default", OwnerReferences: []metav1.OwnerReference{{APIVersion: "v1", Kind: "ServiceAccount", Name: "sa"}}}},
+		// In a namespace without Cluster API objects.
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "baz-kubeconfig", Namespace: "apps", Labels: labels("baz")}},
+	}
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, objs...)
+
+	orphans, err := FindOrphans(context.Background(), c, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, o := range orphans {
+		got = append(got, o.String())
+	}
+	sort.Strings(got)
+	expected := []string{
+		`ClusterResourceSetBinding default/bar: owner Cluster "bar" not found`,
+		`Machine default/m-no-cluster: Cluster "bar" not found`,
+		`Machine other/m-other-namespace: Cluster "foo" not found`,
+		`MachineDeployment default/md-old-cluster: owner Cluster "foo" not found`,
+		`MachineSet default/ms-no-md: owner MachineDeployment "md" not found`,
+		`Secret default/bar-ca: owner Cluster "bar" not found`,
+		`Secret default/bar-kubeconfig: Cluster "bar" not found`,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected orphans:\n%v\ngot:\n%v", expected, got)
+	}
+
+	orphans, err = FindOrphans(context.Background(), c, "other")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(orphans) != 1 || orphans[0].Name != "m-other-namespace" {
+		t.Errorf("expected only the orphans of namespace other, got %v", orphans)
+	}
+
+	var b bytes.Buffer
+	if err := DeleteOrphans(context.Background(), &b, c, orphans); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = c.Get(context.Background(), client.ObjectKey{Namespace: "other", Name: "m-other-namespace"}, &clusterv1.Machine{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected the orphan to be deleted, got %v", err)
+	}
+}
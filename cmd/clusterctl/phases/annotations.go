@@ -0,0 +1,68 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"runtime/debug"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+// Operations recorded in the ClusterctlOperationAnnotation of the objects created by clusterctl.
+const (
+	OperationCreate = "create"
+	OperationPivot  = "pivot"
+)
+
+var (
+	// Version is the version of clusterctl recorded on the objects it creates. It can be set at
+	// build time with -ldflags "-X sigs.k8s.io/cluster-api/cmd/clusterctl/phases.Version=<version>",
+	// the version of the main module is used otherwise.
+	Version = ""
+
+	// now returns the current time, it is replaced in tests.
+	now = time.Now
+)
+
+// annotate records the version of clusterctl, the operation and the current time in the
+// annotations of the objects.
+func annotate(operation string, objs ...metav1.Object) {
+	timestamp := now().UTC().Format(time.RFC3339)
+	for _, obj := range objs {
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[clusterv1.ClusterctlVersionAnnotation] = version()
+		annotations[clusterv1.ClusterctlOperationAnnotation] = operation
+		annotations[clusterv1.ClusterctlTimestampAnnotation] = timestamp
+		obj.SetAnnotations(annotations)
+	}
+}
+
+// version returns the version of clusterctl.
+func version() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "unknown"
+}
@@ -34,6 +34,7 @@ func ApplyCluster(client clusterclient.Client, cluster *clusterv1.Cluster) error
 	}
 
 	klog.Infof("Creating cluster object %v in namespace %q", cluster.Name, cluster.Namespace)
+	annotate(OperationCreate, cluster)
 	if err := client.CreateClusterObject(cluster); err != nil {
 		return err
 	}
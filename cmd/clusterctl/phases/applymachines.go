@@ -34,6 +34,9 @@ func ApplyMachines(client clusterclient.Client, namespace string, machines []*cl
 	}
 
 	klog.Infof("Creating machines in namespace %q", namespace)
+	for _, machine := range machines {
+		annotate(OperationCreate, machine)
+	}
 	if err := client.CreateMachines(machines, namespace); err != nil {
 		return err
 	}
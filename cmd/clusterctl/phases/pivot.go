@@ -222,6 +222,7 @@ func copyMachineClasses(from sourceClient, to targetClient, machineClasses []*cl
 func copyMachineClass(from sourceClient, to targetClient, machineClass *clusterv1.MachineClass) error {
 	// New objects cannot have a specified resource version. Clear it out.
 	machineClass.SetResourceVersion("")
+	annotate(OperationPivot, machineClass)
 	if err := to.CreateMachineClass(machineClass); err != nil {
 		return errors.Wrapf(err, "error copying MachineClass %s/%s to target cluster", machineClass.Namespace, machineClass.Name)
 	}
@@ -240,6 +241,7 @@ func moveCluster(from sourceClient, to targetClient, cluster *clusterv1.Cluster)
 
 	// New objects cannot have a specified resource version. Clear it out.
 	cluster.SetResourceVersion("")
+	annotate(OperationPivot, cluster)
 	if err := to.CreateClusterObject(cluster); err != nil {
 		return errors.Wrapf(err, "error copying Cluster %s/%s to target cluster", cluster.Namespace, cluster.Name)
 	}
@@ -312,6 +314,7 @@ func moveMachineDeployment(from sourceClient, to targetClient, md *clusterv1.Mac
 	// Remove owner reference. This currently assumes that the only owner reference would be a Cluster.
 	md.SetOwnerReferences(nil)
 
+	annotate(OperationPivot, md)
 	if err := to.CreateMachineDeployments([]*clusterv1.MachineDeployment{md}, md.Namespace); err != nil {
 		return errors.Wrapf(err, "error copying MachineDeployment %s/%s to target cluster", md.Namespace, md.Name)
 	}
@@ -356,6 +359,7 @@ func moveMachineSet(from sourceClient, to targetClient, ms *clusterv1.MachineSet
 	// Remove owner reference. This currently assumes that the only owner references would be a MachineDeployment and/or a Cluster.
 	ms.SetOwnerReferences(nil)
 
+	annotate(OperationPivot, ms)
 	if err := to.CreateMachineSets([]*clusterv1.MachineSet{ms}, ms.Namespace); err != nil {
 		return errors.Wrapf(err, "error copying MachineSet %s/%s to target cluster", ms.Namespace, ms.Name)
 	}
@@ -397,6 +401,7 @@ func moveMachine(from sourceClient, to targetClient, m *clusterv1.Machine) error
 	// Remove owner reference. This currently assumes that the only owner references would be a MachineSet and/or a Cluster.
 	m.SetOwnerReferences(nil)
 
+	annotate(OperationPivot, m)
 	if err := to.CreateMachines([]*clusterv1.Machine{m}, m.Namespace); err != nil {
 		return errors.Wrapf(err, "error copying Machine %s/%s to target cluster", m.Namespace, m.Name)
 	}
@@ -519,6 +519,14 @@ func TestPivot(t *testing.T) {
 		t.Logf("target: %v", target.machineClasses)
 		t.Fatal("expected machine classes to pivot")
 	}
+
+	for _, machines := range target.machines {
+		for _, m := range machines {
+			if m.Annotations[clusterv1.ClusterctlOperationAnnotation] != OperationPivot || m.Annotations[clusterv1.ClusterctlVersionAnnotation] == "" {
+				t.Fatalf("expected machine %s/%s to be annotated as pivoted by clusterctl, got %v", m.Namespace, m.Name, m.Annotations)
+			}
+		}
+	}
 }
 
 // An example of testing a failure scenario
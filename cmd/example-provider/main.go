@@ -18,6 +18,9 @@ package main
 
 import (
 	"flag"
+	"net/http"
+	_ "net/http/pprof"
+	"time"
 
 	"k8s.io/klog"
 	clusterapis "sigs.k8s.io/cluster-api/pkg/apis"
@@ -25,8 +28,11 @@ import (
 	"sigs.k8s.io/cluster-api/pkg/client/clientset_generated/clientset"
 	capicluster "sigs.k8s.io/cluster-api/pkg/controller/cluster"
 	capimachine "sigs.k8s.io/cluster-api/pkg/controller/machine"
+	"sigs.k8s.io/cluster-api/pkg/controller/predicates"
+	"sigs.k8s.io/cluster-api/pkg/healthz"
 	"sigs.k8s.io/cluster-api/pkg/provider/example/actuators/cluster"
 	"sigs.k8s.io/cluster-api/pkg/provider/example/actuators/machine"
+	"sigs.k8s.io/cluster-api/pkg/tracing"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
@@ -35,12 +41,62 @@ import (
 func main() {
 	klog.InitFlags(nil)
 	flag.Set("logtostderr", "true")
+	flag.StringVar(&predicates.WatchFilterValue, "watch-filter", "",
+		"Label value the controllers watch for in the cluster.k8s.io/watch-filter label of cluster-api objects. If unspecified, the controllers reconcile all objects.")
+	enableLeaderElection := flag.Bool("enable-leader-election", false,
+		"Enable leader election, so that only one of the replicas of the controllers is active at a time.")
+	leaderElectionNamespace := flag.String("leader-election-namespace", "",
+		"Namespace of the configmap used for leader election. If unspecified, the namespace the controllers run in.")
+	leaderElectionID := flag.String("leader-election-id", "cluster-api-example-provider",
+		"Name of the configmap used for leader election.")
+	leaderElectionLeaseDuration := flag.Duration("leader-election-lease-duration", 15*time.Second,
+		"Duration the non-leader replicas wait before trying to acquire leadership after the last renewal.")
+	leaderElectionRenewDeadline := flag.Duration("leader-election-renew-deadline", 10*time.Second,
+		"Duration the leader retries renewing its leadership before giving it up.")
+	leaderElectionRetryPeriod := flag.Duration("leader-election-retry-period", 2*time.Second,
+		"Interval between the attempts of the replicas to acquire or renew leadership.")
+	syncPeriod := flag.Duration("sync-period", 10*time.Minute,
+		"The minimum interval at which watched resources are reconciled.")
+	flag.IntVar(&capicluster.MaxConcurrentReconciles, "cluster-concurrency", capicluster.MaxConcurrentReconciles,
+		"Number of Clusters to process simultaneously.")
+	flag.IntVar(&capimachine.MaxConcurrentReconciles, "machine-concurrency", capimachine.MaxConcurrentReconciles,
+		"Number of Machines to process simultaneously.")
+	healthAddr := flag.String("health-addr", ":9440",
+		"The address the health and readiness endpoints bind to. Use 0 to disable them.")
+	profilerAddress := flag.String("profiler-address", "",
+		"Bind address to expose the pprof profiler (e.g. localhost:6060). If unspecified, the profiler is disabled.")
+	tracingAddress := flag.String("tracing-address", "",
+		"Address of the OpenCensus agent or OpenTelemetry collector the traces are exported to (e.g. localhost:55678). If unspecified, tracing is disabled.")
+	tracingSampleRatio := flag.Float64("tracing-sample-ratio", 1,
+		"Ratio of the reconciles traced, between 0 and 1.")
 	flag.Parse()
 
+	if *profilerAddress != "" {
+		klog.Infof("Profiler listening for requests at %s", *profilerAddress)
+		go func() {
+			klog.Info(http.ListenAndServe(*profilerAddress, nil))
+		}()
+	}
+
+	if *tracingAddress != "" {
+		klog.Infof("Exporting traces to %s", *tracingAddress)
+		if err := tracing.Setup(*tracingAddress, "cluster-api-example-provider", *tracingSampleRatio); err != nil {
+			klog.Fatal(err)
+		}
+	}
+
 	cfg := config.GetConfigOrDie()
 
 	// Setup a Manager
-	mgr, err := manager.New(cfg, manager.Options{})
+	mgr, err := manager.New(cfg, manager.Options{
+		SyncPeriod:              syncPeriod,
+		LeaderElection:          *enableLeaderElection,
+		LeaderElectionNamespace: *leaderElectionNamespace,
+		LeaderElectionID:        *leaderElectionID,
+		LeaseDuration:           leaderElectionLeaseDuration,
+		RenewDeadline:           leaderElectionRenewDeadline,
+		RetryPeriod:             leaderElectionRetryPeriod,
+	})
 	if err != nil {
 		klog.Fatalf("Failed to set up controller manager: %v", err)
 	}
@@ -68,6 +124,22 @@ func main() {
 	capimachine.AddWithActuator(mgr, machineActuator)
 	capicluster.AddWithActuator(mgr, clusterActuator)
 
+	if *healthAddr != "0" {
+		liveness := &healthz.Handler{}
+		liveness.AddCheck("ping", healthz.Ping)
+
+		readiness := &healthz.Handler{}
+		started := &healthz.ManagerStarted{}
+		if err := mgr.Add(started); err != nil {
+			klog.Fatal(err)
+		}
+		readiness.AddCheck("manager", started.Check)
+
+		go func() {
+			klog.Fatal(http.ListenAndServe(*healthAddr, healthz.NewServeMux(liveness, readiness)))
+		}()
+	}
+
 	if err := mgr.Start(signals.SetupSignalHandler()); err != nil {
 		klog.Fatalf("Failed to run manager: %v", err)
 	}
@@ -18,6 +18,9 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
 	"time"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
@@ -25,7 +28,17 @@ import (
 	"k8s.io/klog/klogr"
 	"sigs.k8s.io/cluster-api/pkg/apis"
 	"sigs.k8s.io/cluster-api/pkg/controller"
+	"sigs.k8s.io/cluster-api/pkg/controller/certexpiry"
+	"sigs.k8s.io/cluster-api/pkg/controller/machinedeployment"
+	"sigs.k8s.io/cluster-api/pkg/controller/machineset"
+	"sigs.k8s.io/cluster-api/pkg/controller/metrics"
+	"sigs.k8s.io/cluster-api/pkg/controller/noderef"
+	"sigs.k8s.io/cluster-api/pkg/controller/predicates"
+	"sigs.k8s.io/cluster-api/pkg/healthz"
+	"sigs.k8s.io/cluster-api/pkg/tracing"
+	"sigs.k8s.io/cluster-api/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/log"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
@@ -36,14 +49,77 @@ func main() {
 	klog.InitFlags(nil)
 	watchNamespace := flag.String("namespace", "",
 		"Namespace that the controller watches to reconcile cluster-api objects. If unspecified, the controller watches for cluster-api objects across all namespaces.")
+	flag.StringVar(&predicates.WatchFilterValue, "watch-filter", "",
+		"Label value the controllers watch for in the cluster.k8s.io/watch-filter label of cluster-api objects. If unspecified, the controllers reconcile all objects.")
+	enableLeaderElection := flag.Bool("enable-leader-election", false,
+		"Enable leader election, so that only one of the replicas of the controllers is active at a time.")
+	leaderElectionNamespace := flag.String("leader-election-namespace", "",
+		"Namespace of the configmap used for leader election. If unspecified, the namespace the controllers run in.")
+	leaderElectionID := flag.String("leader-election-id", "cluster-api-controller-manager",
+		"Name of the configmap used for leader election.")
+	leaderElectionLeaseDuration := flag.Duration("leader-election-lease-duration", 15*time.Second,
+		"Duration the non-leader replicas wait before trying to acquire leadership after the last renewal.")
+	leaderElectionRenewDeadline := flag.Duration("leader-election-renew-deadline", 10*time.Second,
+		"Duration the leader retries renewing its leadership before giving it up.")
+	leaderElectionRetryPeriod := flag.Duration("leader-election-retry-period", 2*time.Second,
+		"Interval between the attempts of the replicas to acquire or renew leadership.")
+	syncPeriod := flag.Duration("sync-period", 10*time.Minute,
+		"The minimum interval at which watched resources are reconciled.")
+	flag.IntVar(&machineset.MaxConcurrentReconciles, "machineset-concurrency", machineset.MaxConcurrentReconciles,
+		"Number of MachineSets to process simultaneously.")
+	flag.IntVar(&machinedeployment.MaxConcurrentReconciles, "machinedeployment-concurrency", machinedeployment.MaxConcurrentReconciles,
+		"Number of MachineDeployments to process simultaneously.")
+	flag.IntVar(&noderef.MaxConcurrentReconciles, "noderef-concurrency", noderef.MaxConcurrentReconciles,
+		"Number of Machines to assign a NodeRef to simultaneously.")
+	flag.IntVar(&certexpiry.APIServerPort, "certexpiry-apiserver-port", certexpiry.APIServerPort,
+		"Port the API server listens on, on control plane Machines, to check the expiry of their certificates.")
+	logFormat := flag.String("log-format", "text",
+		"Format of the logs, either text or json.")
+	metricsAddr := flag.String("metrics-addr", ":8080",
+		"The address the metrics endpoint binds to. Use 0 to disable it.")
+	flag.BoolVar(&metrics.FleetMetrics, "fleet-metrics", metrics.FleetMetrics,
+		"Report the Machines by version and failure domain, and the control planes with a pending upgrade.")
+	healthAddr := flag.String("health-addr", ":9440",
+		"The address the health and readiness endpoints bind to. Use 0 to disable them.")
+	profilerAddress := flag.String("profiler-address", "",
+		"Bind address to expose the pprof profiler (e.g. localhost:6060). If unspecified, the profiler is disabled.")
+	tracingAddress := flag.String("tracing-address", "",
+		"Address of the OpenCensus agent or OpenTelemetry collector the traces are exported to (e.g. localhost:55678). If unspecified, tracing is disabled.")
+	tracingSampleRatio := flag.Float64("tracing-sample-ratio", 1,
+		"Ratio of the reconciles traced, between 0 and 1.")
+	webhookPort := flag.Int("webhook-port", 0,
+		"Port the webhook server serves at. If unspecified, the defaulting and validating webhooks are disabled.")
+	webhookCertDir := flag.String("webhook-cert-dir", "/tmp/k8s-webhook-server/serving-certs",
+		"Directory containing the tls.crt and tls.key files of the webhook server.")
 
 	flag.Parse()
 	if *watchNamespace != "" {
 		klog.Infof("Watching cluster-api objects only in namespace %q for reconciliation", *watchNamespace)
 	}
 
+	if *profilerAddress != "" {
+		klog.Infof("Profiler listening for requests at %s", *profilerAddress)
+		go func() {
+			klog.Info(http.ListenAndServe(*profilerAddress, newProfilerMux()))
+		}()
+	}
+
+	if *tracingAddress != "" {
+		klog.Infof("Exporting traces to %s", *tracingAddress)
+		if err := tracing.Setup(*tracingAddress, "cluster-api-controller-manager", *tracingSampleRatio); err != nil {
+			klog.Fatal(err)
+		}
+	}
+
 	// Setup controller-runtime logger.
-	log.SetLogger(klogr.New())
+	switch *logFormat {
+	case "text":
+		log.SetLogger(klogr.New())
+	case "json":
+		log.SetLogger(zap.Logger(false))
+	default:
+		klog.Fatalf("Unknown log format %q, must be text or json", *logFormat)
+	}
 
 	// Get a config to talk to the api-server.
 	cfg, err := config.GetConfig()
@@ -52,10 +128,17 @@ func main() {
 	}
 
 	// Create a new Cmd to provide shared dependencies and start components.
-	syncPeriod := 10 * time.Minute
 	mgr, err := manager.New(cfg, manager.Options{
-		SyncPeriod: &syncPeriod,
-		Namespace:  *watchNamespace,
+		SyncPeriod:              syncPeriod,
+		Namespace:               *watchNamespace,
+		MetricsBindAddress:      *metricsAddr,
+		Port:                    *webhookPort,
+		LeaderElection:          *enableLeaderElection,
+		LeaderElectionNamespace: *leaderElectionNamespace,
+		LeaderElectionID:        *leaderElectionID,
+		LeaseDuration:           leaderElectionLeaseDuration,
+		RenewDeadline:           leaderElectionRenewDeadline,
+		RetryPeriod:             leaderElectionRetryPeriod,
 	})
 
 	if err != nil {
@@ -74,8 +157,48 @@ func main() {
 		klog.Fatal(err)
 	}
 
+	// Setup all Webhooks.
+	if *webhookPort != 0 {
+		mgr.GetWebhookServer().CertDir = *webhookCertDir
+		if err := webhook.AddToManager(mgr); err != nil {
+			klog.Fatal(err)
+		}
+	}
+
+	// Setup the health and readiness endpoints.
+	if *healthAddr != "0" {
+		liveness := &healthz.Handler{}
+		liveness.AddCheck("ping", healthz.Ping)
+
+		readiness := &healthz.Handler{}
+		started := &healthz.ManagerStarted{}
+		if err := mgr.Add(started); err != nil {
+			klog.Fatal(err)
+		}
+		readiness.AddCheck("manager", started.Check)
+		if *webhookPort != 0 {
+			readiness.AddCheck("webhook", healthz.TLSServer(fmt.Sprintf("localhost:%d", *webhookPort)))
+		}
+
+		go func() {
+			klog.Fatal(http.ListenAndServe(*healthAddr, healthz.NewServeMux(liveness, readiness)))
+		}()
+	}
+
 	klog.Info("Starting the Cmd")
 
 	// Start the Cmd
 	klog.Fatal(mgr.Start(signals.SetupSignalHandler()))
 }
+
+// newProfilerMux returns a mux serving the pprof profiler, rather than registering it on
+// http.DefaultServeMux where any other server using the default mux would expose it.
+func newProfilerMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
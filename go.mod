@@ -3,15 +3,19 @@ module sigs.k8s.io/cluster-api
 go 1.12
 
 require (
+	contrib.go.opencensus.io/exporter/ocagent v0.4.12
 	github.com/Azure/go-autorest/autorest v0.2.0 // indirect
 	github.com/davecgh/go-spew v1.1.1
 	github.com/gophercloud/gophercloud v0.2.0 // indirect
 	github.com/onsi/ginkgo v1.8.0
 	github.com/onsi/gomega v1.5.0
 	github.com/pkg/errors v0.8.1
+	github.com/prometheus/client_golang v0.9.3-0.20190127221311-3c4408c8b829
+	github.com/prometheus/client_model v0.0.0-20190115171406-56726106282f
 	github.com/sergi/go-diff v1.0.0
 	github.com/spf13/cobra v0.0.3
 	github.com/spf13/pflag v1.0.3
+	go.opencensus.io v0.20.2
 	golang.org/x/net v0.0.0-20190501004415-9ce7a6920f09
 	k8s.io/api v0.0.0-20190409021203-6e4e0e4f393b
 	k8s.io/apimachinery v0.0.0-20190404173353-6a84e37a896d
@@ -25,4 +29,5 @@ require (
 	sigs.k8s.io/controller-runtime v0.2.0-beta.2
 	sigs.k8s.io/controller-tools v0.2.0-beta.2.0.20190610175510-203d8e8ab133
 	sigs.k8s.io/testing_frameworks v0.1.2-0.20190130140139-57f07443c2d4
+	sigs.k8s.io/yaml v1.1.0
 )
@@ -17,13 +17,53 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
 )
 
-const ClusterFinalizer = "cluster.cluster.k8s.io"
+const (
+	ClusterFinalizer = "cluster.cluster.k8s.io"
+
+	// ClusterManagedByAnnotation is set on clusters whose infrastructure is managed outside of
+	// Cluster API, e.g. an existing VPC and load balancer. The cluster actuator is not called
+	// for these clusters: whoever manages the infrastructure is expected to fill in
+	// Status.APIEndpoints and Status.FailureDomains, and to tear it down after deletion.
+	ClusterManagedByAnnotation = "cluster.k8s.io/managed-by"
+)
+
+// ClusterConditionType is the type of a condition of a Cluster.
+type ClusterConditionType string
+
+const (
+	// ControlPlaneInitializedCondition is added to clusters with control plane Machines, and
+	// becomes true once one of them has a Node and the API endpoints of the cluster are valid.
+	// It stays true afterwards. MachineSets of worker Machines don't create Machines while it is
+	// false, as they would not be able to join.
+	ControlPlaneInitializedCondition ClusterConditionType = "ControlPlaneInitialized"
+
+	// ControlPlaneReadyCondition is added to clusters with control plane Machines, and is true
+	// while all of them have a Node.
+	ControlPlaneReadyCondition ClusterConditionType = "ControlPlaneReady"
+
+	// APIEndpointsValidCondition is added to clusters reporting API endpoints, and is false
+	// while one of them has an invalid port or a host that can't be resolved.
+	APIEndpointsValidCondition ClusterConditionType = "APIEndpointsValid"
+
+	// WorkersDeletedCondition is added to deleted clusters, and becomes true once the
+	// worker MachineDeployments, MachineSets and Machines of the cluster are gone.
+	WorkersDeletedCondition ClusterConditionType = "WorkersDeleted"
+
+	// ControlPlaneDeletedCondition is added to deleted clusters once their workers are
+	// deleted, and becomes true once the control plane Machines of the cluster are gone.
+	ControlPlaneDeletedCondition ClusterConditionType = "ControlPlaneDeleted"
+
+	// InfrastructureDeletedCondition is added to deleted clusters once their control plane
+	// is deleted, while the cluster actuator deletes the infrastructure of the cluster.
+	InfrastructureDeletedCondition ClusterConditionType = "InfrastructureDeleted"
+)
 
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -56,10 +96,45 @@ type ClusterSpec struct {
 	// serialized/deserialized from this field.
 	// +optional
 	ProviderSpec ProviderSpec `json:"providerSpec,omitempty"`
+
+	// Addons configures how the core addons of the cluster are upgraded
+	// when the control plane version changes.
+	// +optional
+	Addons *ClusterAddons `json:"addons,omitempty"`
 }
 
 /// [ClusterSpec]
 
+/// [ClusterAddons]
+// ClusterAddons configures the upgrade of the core addons deployed by kubeadm.
+type ClusterAddons struct {
+	// CoreDNS configures the upgrade of the CoreDNS deployment.
+	// +optional
+	CoreDNS *AddonUpgrade `json:"coreDNS,omitempty"`
+
+	// KubeProxy configures the upgrade of the kube-proxy daemonset.
+	// +optional
+	KubeProxy *AddonUpgrade `json:"kubeProxy,omitempty"`
+}
+
+/// [ClusterAddons]
+
+/// [AddonUpgrade]
+// AddonUpgrade configures the upgrade of a single addon.
+type AddonUpgrade struct {
+	// Skip disables the upgrade of the addon, it is left untouched
+	// when the control plane version changes.
+	// +optional
+	Skip bool `json:"skip,omitempty"`
+
+	// ImageTag pins the image tag of the addon instead of deriving it
+	// from the control plane version.
+	// +optional
+	ImageTag string `json:"imageTag,omitempty"`
+}
+
+/// [AddonUpgrade]
+
 /// [ClusterNetworkingConfig]
 // ClusterNetworkingConfig specifies the different networking
 // parameters for a cluster.
@@ -91,6 +166,12 @@ type ClusterStatus struct {
 	// +optional
 	APIEndpoints []APIEndpoint `json:"apiEndpoints,omitempty"`
 
+	// FailureDomains lists the failure domains, e.g. availability zones,
+	// Machines of the cluster can be spread across. It is reported by the
+	// cluster actuator.
+	// +optional
+	FailureDomains []string `json:"failureDomains,omitempty"`
+
 	// NB: Eventually we will redefine ErrorReason as ClusterStatusError once the
 	// following issue is fixed.
 	// https://github.com/kubernetes-incubator/apiserver-builder/issues/176
@@ -106,6 +187,11 @@ type ClusterStatus struct {
 	// +optional
 	ErrorMessage string `json:"errorMessage,omitempty"`
 
+	// Conditions are the observations of the state of the cluster, like the initialization
+	// of its control plane or the progress of its deletion.
+	// +optional
+	Conditions []ClusterCondition `json:"conditions,omitempty"`
+
 	// Provider-specific status.
 	// It is recommended that providers maintain their
 	// own versioned API types that should be
@@ -116,6 +202,30 @@ type ClusterStatus struct {
 
 /// [ClusterStatus]
 
+/// [ClusterCondition]
+// ClusterCondition is an observation of the state of a Cluster.
+type ClusterCondition struct {
+	// Type of the condition.
+	Type ClusterConditionType `json:"type"`
+
+	// Status of the condition, one of True, False or Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+
+	// LastTransitionTime is the last time the condition changed from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// Reason is a brief reason for the last transition of the condition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human readable description of the condition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+/// [ClusterCondition]
+
 /// [APIEndpoint]
 // APIEndpoint represents a reachable Kubernetes API endpoint.
 type APIEndpoint struct {
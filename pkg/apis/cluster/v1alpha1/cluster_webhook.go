@@ -0,0 +1,106 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Default implements admission.Defaulter so a webhook will be registered for the type.
+func (c *Cluster) Default() {
+	PopulateDefaultsCluster(c)
+}
+
+// ValidateCreate implements admission.Validator so a webhook will be registered for the type.
+func (c *Cluster) ValidateCreate() error {
+	return c.validateClusterNetwork()
+}
+
+// ValidateUpdate implements admission.Validator so a webhook will be registered for the type.
+// Only changes to the cluster network are validated, so that Clusters created before the webhook
+// was deployed, or being deleted, can still be updated.
+func (c *Cluster) ValidateUpdate(old runtime.Object) error {
+	oldCluster, ok := old.(*Cluster)
+	if !ok {
+		return apierrors.NewBadRequest("expected a Cluster")
+	}
+	if c.DeletionTimestamp != nil || reflect.DeepEqual(oldCluster.Spec.ClusterNetwork, c.Spec.ClusterNetwork) {
+		return nil
+	}
+	return c.validateClusterNetwork()
+}
+
+// validateClusterNetwork checks the CIDR blocks and the service domain of the cluster network
+// are valid, so that errors show up when applying the Cluster rather than when bootstrapping it.
+func (c *Cluster) validateClusterNetwork() error {
+	fldPath := field.NewPath("spec", "clusterNetwork")
+	network := c.Spec.ClusterNetwork
+
+	services, allErrs := parseCIDRBlocks(network.Services.CIDRBlocks, fldPath.Child("services", "cidrBlocks"))
+	pods, errs := parseCIDRBlocks(network.Pods.CIDRBlocks, fldPath.Child("pods", "cidrBlocks"))
+	allErrs = append(allErrs, errs...)
+
+	for _, s := range services {
+		for _, p := range pods {
+			if overlaps(s, p) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("services", "cidrBlocks"), s.String(),
+					fmt.Sprintf("overlaps with pods CIDR block %s", p.String())))
+			}
+		}
+	}
+
+	if network.ServiceDomain != "" {
+		if msgs := validation.IsDNS1123Subdomain(network.ServiceDomain); len(msgs) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("serviceDomain"), network.ServiceDomain,
+				strings.Join(msgs, "; ")))
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(SchemeGroupVersion.WithKind("Cluster").GroupKind(), c.Name, allErrs)
+}
+
+// parseCIDRBlocks returns the networks of the CIDR blocks at fldPath that could be parsed,
+// and an error for each of those that couldn't.
+func parseCIDRBlocks(blocks []string, fldPath *field.Path) ([]*net.IPNet, field.ErrorList) {
+	var networks []*net.IPNet
+	var allErrs field.ErrorList
+	for i, block := range blocks {
+		_, network, err := net.ParseCIDR(block)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), block, "must be a valid CIDR block"))
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks, allErrs
+}
+
+// overlaps returns true if the networks share any address.
+func overlaps(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
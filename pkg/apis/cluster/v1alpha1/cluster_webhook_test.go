@@ -0,0 +1,177 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClusterDefault(t *testing.T) {
+	c := &Cluster{}
+	c.Spec.ClusterNetwork.Services.CIDRBlocks = []string{"10.0.0.0/16"}
+	c.Default()
+
+	if c.Spec.ClusterNetwork.ServiceDomain != "cluster.local" {
+		t.Errorf("expected default service domain 'cluster.local', got '%s'", c.Spec.ClusterNetwork.ServiceDomain)
+	}
+	if got := c.Spec.ClusterNetwork.Services.CIDRBlocks; len(got) != 1 || got[0] != "10.0.0.0/16" {
+		t.Errorf("expected services CIDR blocks to be kept, got %v", got)
+	}
+	if len(c.Spec.ClusterNetwork.Pods.CIDRBlocks) != 0 {
+		t.Errorf("expected pods CIDR blocks not to be defaulted, got %v", c.Spec.ClusterNetwork.Pods.CIDRBlocks)
+	}
+}
+
+func TestClusterDefaultServices(t *testing.T) {
+	tests := []struct {
+		name     string
+		pods     []string
+		expected []string
+	}{
+		{
+			name:     "no pods CIDR blocks",
+			expected: []string{"10.96.0.0/12"},
+		},
+		{
+			name:     "distinct pods CIDR blocks",
+			pods:     []string{"192.168.0.0/16"},
+			expected: []string{"10.96.0.0/12"},
+		},
+		{
+			name: "overlapping pods CIDR blocks",
+			pods: []string{"10.0.0.0/8"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Cluster{}
+			c.Spec.ClusterNetwork.Pods.CIDRBlocks = tt.pods
+			c.Default()
+
+			if got := c.Spec.ClusterNetwork.Services.CIDRBlocks; len(got) != len(tt.expected) || (len(got) == 1 && got[0] != tt.expected[0]) {
+				t.Errorf("expected services CIDR blocks %v, got %v", tt.expected, got)
+			}
+			if err := c.ValidateCreate(); err != nil {
+				t.Errorf("expected defaulted Cluster to be valid, got %v", err)
+			}
+		})
+	}
+}
+
+func TestClusterValidateCreate(t *testing.T) {
+	tests := []struct {
+		name          string
+		services      []string
+		pods          []string
+		serviceDomain string
+		expectErr     bool
+	}{
+		{
+			name:          "valid network",
+			services:      []string{"10.96.0.0/12"},
+			pods:          []string{"192.168.0.0/16"},
+			serviceDomain: "cluster.local",
+		},
+		{
+			name: "empty network",
+		},
+		{
+			name:      "invalid services CIDR block",
+			services:  []string{"10.96.0.0"},
+			expectErr: true,
+		},
+		{
+			name:      "invalid pods CIDR block",
+			pods:      []string{"192.168.0.0/33"},
+			expectErr: true,
+		},
+		{
+			name:      "overlapping CIDR blocks",
+			services:  []string{"10.96.0.0/12"},
+			pods:      []string{"10.100.0.0/16"},
+			expectErr: true,
+		},
+		{
+			name:          "invalid service domain",
+			serviceDomain: "cluster_local",
+			expectErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Cluster{}
+			c.Spec.ClusterNetwork.Services.CIDRBlocks = tt.services
+			c.Spec.ClusterNetwork.Pods.CIDRBlocks = tt.pods
+			c.Spec.ClusterNetwork.ServiceDomain = tt.serviceDomain
+
+			err := c.ValidateCreate()
+			if (err != nil) != tt.expectErr {
+				t.Errorf("expected error: %v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestClusterValidateUpdate(t *testing.T) {
+	invalid := func() *Cluster {
+		c := &Cluster{}
+		c.Spec.ClusterNetwork.Services.CIDRBlocks = []string{"10.96.0.0/12"}
+		c.Spec.ClusterNetwork.Pods.CIDRBlocks = []string{"10.0.0.0/8"}
+		return c
+	}
+
+	tests := []struct {
+		name      string
+		update    func(c *Cluster)
+		expectErr bool
+	}{
+		{
+			name:   "unchanged invalid network",
+			update: func(c *Cluster) { c.Labels = map[string]string{"foo": "bar"} },
+		},
+		{
+			name:      "changed invalid network",
+			update:    func(c *Cluster) { c.Spec.ClusterNetwork.ServiceDomain = "cluster.local" },
+			expectErr: true,
+		},
+		{
+			name: "deleted Cluster",
+			update: func(c *Cluster) {
+				now := metav1.Now()
+				c.DeletionTimestamp = &now
+				c.Spec.ClusterNetwork.ServiceDomain = "cluster.local"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := invalid()
+			c := old.DeepCopy()
+			tt.update(c)
+
+			err := c.ValidateUpdate(old)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("expected error: %v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
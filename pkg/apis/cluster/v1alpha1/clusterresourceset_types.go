@@ -0,0 +1,117 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ClusterResourceSetSecretType is the type of the Secrets that can be referenced by
+	// ClusterResourceSets. Secrets of other types are never applied to workload clusters.
+	ClusterResourceSetSecretType corev1.SecretType = "cluster.k8s.io/resource-set"
+
+	// ClusterResourceSetSkipReconcileAnnotation can be set on the objects of the manifests of
+	// a ClusterResourceSet, or on the objects created from them in a workload cluster, for the
+	// controller to leave the objects untouched once created.
+	ClusterResourceSetSkipReconcileAnnotation = "cluster.k8s.io/resource-set-skip-reconcile"
+)
+
+// ClusterResourceSetStrategy is how the resources of a ClusterResourceSet are kept applied.
+type ClusterResourceSetStrategy string
+
+const (
+	// ClusterResourceSetStrategyApplyOnce applies the resources again only when their values change.
+	ClusterResourceSetStrategyApplyOnce ClusterResourceSetStrategy = "ApplyOnce"
+
+	// ClusterResourceSetStrategyReconcile also re-creates the objects deleted from the workload
+	// clusters and reverts the changes made to them.
+	ClusterResourceSetStrategyReconcile ClusterResourceSetStrategy = "Reconcile"
+)
+
+// ClusterResourceSetResourceKind is the kind of a resource of a ClusterResourceSet.
+type ClusterResourceSetResourceKind string
+
+const (
+	ConfigMapClusterResourceSetResourceKind ClusterResourceSetResourceKind = "ConfigMap"
+	SecretClusterResourceSetResourceKind    ClusterResourceSetResourceKind = "Secret"
+)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+/// [ClusterResourceSet]
+// ClusterResourceSet applies the manifests of a set of ConfigMaps and Secrets to the
+// workload clusters it selects, like the CNI or CSI driver of the clusters.
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=clusterresourcesets,shortName=crs
+type ClusterResourceSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterResourceSetSpec `json:"spec,omitempty"`
+}
+
+/// [ClusterResourceSet]
+
+/// [ClusterResourceSetSpec]
+// ClusterResourceSetSpec defines the desired state of ClusterResourceSet
+type ClusterResourceSetSpec struct {
+	// ClusterSelector selects the Clusters, in the namespace of the ClusterResourceSet,
+	// the resources are applied to. An empty selector selects no Cluster.
+	ClusterSelector metav1.LabelSelector `json:"clusterSelector"`
+
+	// Resources are the ConfigMaps and Secrets, in the namespace of the ClusterResourceSet,
+	// whose values are the manifests to apply. The objects of the manifests are created
+	// in the workload clusters once their control plane is reachable, in the order of
+	// the resources and of the keys of their values.
+	// +optional
+	Resources []ResourceRef `json:"resources,omitempty"`
+
+	// Strategy is how the resources are kept applied to the workload clusters, ApplyOnce
+	// or Reconcile. Defaults to ApplyOnce.
+	// +kubebuilder:validation:Enum=ApplyOnce;Reconcile
+	// +optional
+	Strategy ClusterResourceSetStrategy `json:"strategy,omitempty"`
+}
+
+/// [ClusterResourceSetSpec]
+
+// ResourceRef references a ConfigMap or a Secret of a ClusterResourceSet.
+type ResourceRef struct {
+	// Name of the resource.
+	Name string `json:"name"`
+
+	// Kind of the resource, ConfigMap or Secret.
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	Kind ClusterResourceSetResourceKind `json:"kind"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterResourceSetList contains a list of ClusterResourceSet
+type ClusterResourceSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterResourceSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterResourceSet{}, &ClusterResourceSetList{})
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+/// [ClusterResourceSetBinding]
+// ClusterResourceSetBinding records the resources of ClusterResourceSets applied to
+// a Cluster. It has the name of the Cluster and is deleted along with it.
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=clusterresourcesetbindings,shortName=crsb
+type ClusterResourceSetBinding struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterResourceSetBindingSpec `json:"spec,omitempty"`
+}
+
+/// [ClusterResourceSetBinding]
+
+/// [ClusterResourceSetBindingSpec]
+// ClusterResourceSetBindingSpec defines the resources applied to a Cluster
+type ClusterResourceSetBindingSpec struct {
+	// Bindings are the ClusterResourceSets which selected the Cluster.
+	// +optional
+	Bindings []ResourceSetBinding `json:"bindings,omitempty"`
+}
+
+/// [ClusterResourceSetBindingSpec]
+
+// ResourceSetBinding records the resources of a ClusterResourceSet applied to a Cluster.
+type ResourceSetBinding struct {
+	// ClusterResourceSetName is the name of the ClusterResourceSet.
+	ClusterResourceSetName string `json:"clusterResourceSetName"`
+
+	// Resources are the resources of the ClusterResourceSet the controller tried to apply.
+	// +optional
+	Resources []ResourceBinding `json:"resources,omitempty"`
+}
+
+// ResourceBinding records the last application of a resource to a Cluster.
+type ResourceBinding struct {
+	ResourceRef `json:",inline"`
+
+	// Hash is the hash of the values of the resource when it was last applied.
+	// +optional
+	Hash string `json:"hash,omitempty"`
+
+	// LastAppliedTime is the time the resource was last applied.
+	// +optional
+	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+
+	// Applied is true if all the objects of the resource were applied.
+	Applied bool `json:"applied"`
+
+	// ErrorMessage describes why the resource could not be applied.
+	// +optional
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterResourceSetBindingList contains a list of ClusterResourceSetBinding
+type ClusterResourceSetBindingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterResourceSetBinding `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterResourceSetBinding{}, &ClusterResourceSetBindingList{})
+}
@@ -22,6 +22,20 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+const (
+	// WatchFilterLabelName is the label partitioning Cluster API objects between controller instances.
+	// Controllers started with a watch filter only reconcile objects whose label matches it.
+	WatchFilterLabelName = "cluster.k8s.io/watch-filter"
+
+	// ClusterctlVersionAnnotation, ClusterctlOperationAnnotation and ClusterctlTimestampAnnotation
+	// record the version of clusterctl, the operation (create or pivot) and the time at which
+	// clusterctl last created an object. They are not copied from MachineDeployments to their
+	// MachineSets, which are created by the controllers.
+	ClusterctlVersionAnnotation   = "cluster.k8s.io/clusterctl-version"
+	ClusterctlOperationAnnotation = "cluster.k8s.io/clusterctl-operation"
+	ClusterctlTimestampAnnotation = "cluster.k8s.io/clusterctl-timestamp"
+)
+
 // ProviderSpec defines the configuration to use during node creation.
 type ProviderSpec struct {
 
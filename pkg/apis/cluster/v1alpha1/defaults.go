@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"net"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
@@ -72,3 +74,56 @@ func PopulateDefaultsMachineDeployment(d *MachineDeployment) {
 		d.Namespace = metav1.NamespaceDefault
 	}
 }
+
+// PopulateDefaultsCluster fills in the default networking configuration of a Cluster,
+// matching the defaults kubeadm uses when bootstrapping a control plane.
+// The services CIDR block is left empty if the default would overlap with the pods CIDR blocks.
+func PopulateDefaultsCluster(c *Cluster) {
+	if c.Spec.ClusterNetwork.ServiceDomain == "" {
+		c.Spec.ClusterNetwork.ServiceDomain = "cluster.local"
+	}
+
+	if len(c.Spec.ClusterNetwork.Services.CIDRBlocks) == 0 && !overlapsPods(c, defaultServicesCIDRBlock) {
+		c.Spec.ClusterNetwork.Services.CIDRBlocks = []string{defaultServicesCIDRBlock}
+	}
+
+	if len(c.Namespace) == 0 {
+		c.Namespace = metav1.NamespaceDefault
+	}
+}
+
+const defaultServicesCIDRBlock = "10.96.0.0/12"
+
+// overlapsPods returns true if the CIDR block overlaps with any valid pods CIDR block of the Cluster.
+func overlapsPods(c *Cluster, block string) bool {
+	_, network, err := net.ParseCIDR(block)
+	if err != nil {
+		return false
+	}
+	pods, _ := parseCIDRBlocks(c.Spec.ClusterNetwork.Pods.CIDRBlocks, nil)
+	for _, p := range pods {
+		if overlaps(network, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// populateDefaultsSelector wires the selector and the template labels of a MachineSet or
+// MachineDeployment together when only one of them is set, so the selector always matches
+// the Machines created from the template.
+func populateDefaultsSelector(selector *metav1.LabelSelector, template *MachineTemplateSpec) {
+	if len(selector.MatchLabels)+len(selector.MatchExpressions) == 0 && len(template.Labels) > 0 {
+		selector.MatchLabels = make(map[string]string, len(template.Labels))
+		for k, v := range template.Labels {
+			selector.MatchLabels[k] = v
+		}
+	}
+
+	if len(template.Labels) == 0 && len(selector.MatchLabels) > 0 {
+		template.Labels = make(map[string]string, len(selector.MatchLabels))
+		for k, v := range selector.MatchLabels {
+			template.Labels[k] = v
+		}
+	}
+}
@@ -29,6 +29,21 @@ const (
 
 	// MachineClusterLabelName is the label set on machines linked to a cluster.
 	MachineClusterLabelName = "cluster.k8s.io/cluster-name"
+
+	// MachineRenewCertificatesAnnotation is set on control plane machines whose certificates are
+	// about to expire. Actuators are expected to renew the certificates, either in place or by
+	// replacing the machine, and remove the annotation once done.
+	MachineRenewCertificatesAnnotation = "cluster.k8s.io/renew-certificates"
+
+	// AllowReservedLabelChangesAnnotation can be set on Machines, MachineSets and MachineDeployments
+	// to change or remove their reserved labels, e.g. to move them to another Cluster. It is meant
+	// for break-glass operations only and should be removed right after.
+	AllowReservedLabelChangesAnnotation = "cluster.k8s.io/allow-reserved-label-changes"
+
+	// MachineFailedSinceAnnotation is set by the MachineSet controller on failed Machines, i.e.
+	// Machines whose Status.ErrorReason or Status.ErrorMessage is set, to the time it first saw
+	// them failed, in RFC 3339 format.
+	MachineFailedSinceAnnotation = "cluster.k8s.io/failed-since"
 )
 
 // +genclient
@@ -103,6 +118,12 @@ type MachineSpec struct {
 	// be interfacing with cluster-api as generic provider.
 	// +optional
 	ProviderID *string `json:"providerID,omitempty"`
+
+	// FailureDomain is the failure domain, as reported in the Cluster status,
+	// the Machine should be placed in. It is set by the MachineSet controller
+	// to spread control plane Machines, and must be honored by actuators.
+	// +optional
+	FailureDomain *string `json:"failureDomain,omitempty"`
 }
 
 /// [MachineSpec]
@@ -202,6 +223,16 @@ type MachineStatus struct {
 	// E.g. Pending, Running, Terminating, Failed etc.
 	// +optional
 	Phase *string `json:"phase,omitempty"`
+
+	// CertificatesExpiryDate is the expiry date of the certificate served by the
+	// Kubernetes API server running on this machine. It is only set on control plane machines.
+	// +optional
+	CertificatesExpiryDate *metav1.Time `json:"certificatesExpiryDate,omitempty"`
+
+	// CertificatesDaysRemaining is the number of days left before CertificatesExpiryDate,
+	// as of the last check of the certificate. It is only set on control plane machines.
+	// +optional
+	CertificatesDaysRemaining *int32 `json:"certificatesDaysRemaining,omitempty"`
 }
 
 // LastOperation represents the detail of the last performed operation on the MachineObject.
@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// machineTemplateHashLabelName is the label the MachineDeployment controller selects the
+// MachineSets it owns, and their Machines, with.
+const machineTemplateHashLabelName = "machine-template-hash"
+
+// ValidateCreate implements admission.Validator so a webhook will be registered for the type.
+func (m *Machine) ValidateCreate() error {
+	return nil
+}
+
+// ValidateUpdate implements admission.Validator so a webhook will be registered for the type.
+// The reserved labels of a Machine and its provider ID can't be changed once set.
+func (m *Machine) ValidateUpdate(old runtime.Object) error {
+	oldM, ok := old.(*Machine)
+	if !ok {
+		return apierrors.NewBadRequest("expected a Machine")
+	}
+
+	allErrs := validateReservedLabels(m.Annotations, oldM.Labels, m.Labels, field.NewPath("metadata", "labels"))
+
+	if oldM.Spec.ProviderID != nil && *oldM.Spec.ProviderID != "" &&
+		(m.Spec.ProviderID == nil || *m.Spec.ProviderID != *oldM.Spec.ProviderID) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "providerID"), "cannot be changed once set"))
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(SchemeGroupVersion.WithKind("Machine").GroupKind(), m.Name, allErrs)
+}
+
+// isReservedLabel returns true if the label is used by Cluster API controllers to find the Cluster
+// or the owner of an object. Other labels, such as WatchFilterLabelName, can be changed at any time.
+func isReservedLabel(key string) bool {
+	return key == MachineClusterLabelName || key == machineTemplateHashLabelName
+}
+
+// validateReservedLabels returns an error for each reserved label at fldPath that is changed or
+// removed once set, as the objects would otherwise be orphaned or reconciled against another
+// Cluster. The check is skipped when annotations hold AllowReservedLabelChangesAnnotation.
+func validateReservedLabels(annotations, oldLabels, newLabels map[string]string, fldPath *field.Path) field.ErrorList {
+	if _, ok := annotations[AllowReservedLabelChangesAnnotation]; ok {
+		return nil
+	}
+
+	keys := make([]string, 0, len(oldLabels))
+	for k := range oldLabels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var allErrs field.ErrorList
+	for _, k := range keys {
+		if !isReservedLabel(k) || oldLabels[k] == "" {
+			continue
+		}
+		if v, ok := newLabels[k]; !ok || v != oldLabels[k] {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Key(k), "reserved label cannot be changed once set"))
+		}
+	}
+	return allErrs
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMachineValidateUpdate(t *testing.T) {
+	providerID := "aws:///us-east-1a/i-1234"
+	otherProviderID := "aws:///us-east-1a/i-5678"
+
+	tests := []struct {
+		name      string
+		old       *Machine
+		new       *Machine
+		expectErr bool
+	}{
+		{
+			name: "cluster label set",
+			old:  &Machine{},
+			new:  &Machine{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{MachineClusterLabelName: "foo"}}},
+		},
+		{
+			name:      "cluster label changed",
+			old:       &Machine{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{MachineClusterLabelName: "foo"}}},
+			new:       &Machine{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{MachineClusterLabelName: "bar"}}},
+			expectErr: true,
+		},
+		{
+			name:      "cluster label removed",
+			old:       &Machine{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{MachineClusterLabelName: "foo"}}},
+			new:       &Machine{},
+			expectErr: true,
+		},
+		{
+			name: "watch filter label changed",
+			old:  &Machine{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{WatchFilterLabelName: "foo"}}},
+			new:  &Machine{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{WatchFilterLabelName: "bar"}}},
+		},
+		{
+			name: "other cluster.k8s.io label removed",
+			old:  &Machine{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"cluster.k8s.io/foo": "bar"}}},
+			new:  &Machine{},
+		},
+		{
+			name:      "machine template hash label changed",
+			old:       &Machine{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"machine-template-hash": "1234"}}},
+			new:       &Machine{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"machine-template-hash": "5678"}}},
+			expectErr: true,
+		},
+		{
+			name: "user label changed",
+			old:  &Machine{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"foo": "bar"}}},
+			new:  &Machine{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"foo": "baz"}}},
+		},
+		{
+			name: "cluster label changed with override annotation",
+			old:  &Machine{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{MachineClusterLabelName: "foo"}}},
+			new: &Machine{ObjectMeta: metav1.ObjectMeta{
+				Labels:      map[string]string{MachineClusterLabelName: "bar"},
+				Annotations: map[string]string{AllowReservedLabelChangesAnnotation: "true"},
+			}},
+		},
+		{
+			name: "provider ID set",
+			old:  &Machine{},
+			new:  &Machine{Spec: MachineSpec{ProviderID: &providerID}},
+		},
+		{
+			name:      "provider ID changed",
+			old:       &Machine{Spec: MachineSpec{ProviderID: &providerID}},
+			new:       &Machine{Spec: MachineSpec{ProviderID: &otherProviderID}},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.new.ValidateUpdate(tt.old)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("expected error: %v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
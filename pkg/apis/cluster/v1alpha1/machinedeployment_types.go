@@ -55,7 +55,8 @@ type MachineDeploymentSpec struct {
 	// +optional
 	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
 
-	// Indicates that the deployment is paused.
+	// Indicates that the deployment is paused. Paused deployments are still
+	// scaled, but changes to their template are not rolled out.
 	// +optional
 	Paused bool `json:"paused,omitempty"`
 
@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Default implements admission.Defaulter so a webhook will be registered for the type.
+func (m *MachineDeployment) Default() {
+	PopulateDefaultsMachineDeployment(m)
+	populateDefaultsSelector(&m.Spec.Selector, &m.Spec.Template)
+}
+
+// ValidateCreate implements admission.Validator so a webhook will be registered for the type.
+func (m *MachineDeployment) ValidateCreate() error {
+	allErrs := validateControlPlaneReplicas(m.Spec.Replicas, &m.Spec.Template, field.NewPath("spec", "replicas"))
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(SchemeGroupVersion.WithKind("MachineDeployment").GroupKind(), m.Name, allErrs)
+}
+
+// ValidateUpdate implements admission.Validator so a webhook will be registered for the type.
+// The selector of a MachineDeployment and the reserved labels of its template can't be changed once set,
+// and the replicas of control plane Machines can't be changed to 0 or an even count.
+func (m *MachineDeployment) ValidateUpdate(old runtime.Object) error {
+	oldMD, ok := old.(*MachineDeployment)
+	if !ok {
+		return apierrors.NewBadRequest("expected a MachineDeployment")
+	}
+
+	specPath := field.NewPath("spec")
+	allErrs := validateSelector(&oldMD.Spec.Selector, &m.Spec.Selector, specPath.Child("selector"))
+	allErrs = append(allErrs, validateReservedLabels(m.Annotations, oldMD.Spec.Template.Labels, m.Spec.Template.Labels,
+		specPath.Child("template", "metadata", "labels"))...)
+	if !apiequality.Semantic.DeepEqual(oldMD.Spec.Replicas, m.Spec.Replicas) || !isControlPlaneTemplate(&oldMD.Spec.Template) {
+		allErrs = append(allErrs, validateControlPlaneReplicas(m.Spec.Replicas, &m.Spec.Template, specPath.Child("replicas"))...)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(SchemeGroupVersion.WithKind("MachineDeployment").GroupKind(), m.Name, allErrs)
+}
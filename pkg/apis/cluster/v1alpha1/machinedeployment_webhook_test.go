@@ -0,0 +1,115 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newControlPlaneMachineDeployment returns a MachineDeployment of control plane Machines with the given replicas.
+func newControlPlaneMachineDeployment(replicas int32) *MachineDeployment {
+	md := &MachineDeployment{Spec: MachineDeploymentSpec{Replicas: &replicas}}
+	md.Spec.Template.Spec.Versions.ControlPlane = "1.15.3"
+	return md
+}
+
+func TestMachineDeploymentValidateCreate(t *testing.T) {
+	if err := newControlPlaneMachineDeployment(3).ValidateCreate(); err != nil {
+		t.Errorf("expected no error for odd control plane replicas, got %v", err)
+	}
+	if err := newControlPlaneMachineDeployment(0).ValidateCreate(); err == nil {
+		t.Error("expected error for zero control plane replicas, got nil")
+	}
+	if err := newControlPlaneMachineDeployment(2).ValidateCreate(); err == nil {
+		t.Error("expected error for even control plane replicas, got nil")
+	}
+}
+
+func TestMachineDeploymentValidateUpdate(t *testing.T) {
+	selector := metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}}
+	otherSelector := metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+		{Key: "foo", Operator: metav1.LabelSelectorOpExists},
+	}}
+
+	tests := []struct {
+		name      string
+		old       *MachineDeployment
+		new       *MachineDeployment
+		expectErr bool
+	}{
+		{
+			name: "selector unchanged",
+			old:  &MachineDeployment{Spec: MachineDeploymentSpec{Selector: selector}},
+			new:  &MachineDeployment{Spec: MachineDeploymentSpec{Selector: selector, Paused: true}},
+		},
+		{
+			name:      "selector changed",
+			old:       &MachineDeployment{Spec: MachineDeploymentSpec{Selector: selector}},
+			new:       &MachineDeployment{Spec: MachineDeploymentSpec{Selector: otherSelector}},
+			expectErr: true,
+		},
+		{
+			name: "template cluster label changed",
+			old: &MachineDeployment{Spec: MachineDeploymentSpec{Template: MachineTemplateSpec{
+				ObjectMeta: ObjectMeta{Labels: map[string]string{MachineClusterLabelName: "foo"}},
+			}}},
+			new: &MachineDeployment{Spec: MachineDeploymentSpec{Template: MachineTemplateSpec{
+				ObjectMeta: ObjectMeta{Labels: map[string]string{MachineClusterLabelName: "bar"}},
+			}}},
+			expectErr: true,
+		},
+		{
+			name:      "control plane scaled to zero",
+			old:       newControlPlaneMachineDeployment(3),
+			new:       newControlPlaneMachineDeployment(0),
+			expectErr: true,
+		},
+		{
+			name: "control plane with even replicas unchanged",
+			old:  newControlPlaneMachineDeployment(2),
+			new:  newControlPlaneMachineDeployment(2),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.new.ValidateUpdate(tt.old)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("expected error: %v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestMachineDeploymentDefault(t *testing.T) {
+	md := &MachineDeployment{Spec: MachineDeploymentSpec{Template: MachineTemplateSpec{
+		ObjectMeta: ObjectMeta{Labels: map[string]string{"foo": "bar"}},
+	}}}
+	md.Default()
+
+	if md.Spec.Replicas == nil || *md.Spec.Replicas != 1 {
+		t.Errorf("expected default replicas 1, got %v", md.Spec.Replicas)
+	}
+	if md.Spec.Strategy == nil || md.Spec.Strategy.RollingUpdate == nil {
+		t.Fatalf("expected default rolling update strategy, got %v", md.Spec.Strategy)
+	}
+	if got := md.Spec.Selector.MatchLabels["foo"]; got != "bar" {
+		t.Errorf("expected selector to be defaulted from template labels, got %v", md.Spec.Selector)
+	}
+}
@@ -26,6 +26,12 @@ import (
 	"sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
 )
 
+const (
+	// FailedMachineGracePeriodAnnotation can be set on MachineSets and MachineDeployments to a
+	// duration, e.g. "30m", after which their failed Machines are deleted so that they are replaced.
+	FailedMachineGracePeriodAnnotation = "cluster.k8s.io/failed-machine-grace-period"
+)
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -206,6 +212,8 @@ func (m *MachineSet) Default() {
 		log.Printf("Defaulting to %s\n", randomPolicy)
 		m.Spec.DeletePolicy = randomPolicy
 	}
+
+	populateDefaultsSelector(&m.Spec.Selector, &m.Spec.Template)
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -0,0 +1,100 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidateCreate implements admission.Validator so a webhook will be registered for the type.
+// The replicas of MachineSets managed by a MachineDeployment are not validated, as it scales
+// them through 0 and even counts during rollouts.
+func (m *MachineSet) ValidateCreate() error {
+	if metav1.GetControllerOf(m) != nil {
+		return nil
+	}
+	allErrs := validateControlPlaneReplicas(m.Spec.Replicas, &m.Spec.Template, field.NewPath("spec", "replicas"))
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(SchemeGroupVersion.WithKind("MachineSet").GroupKind(), m.Name, allErrs)
+}
+
+// ValidateUpdate implements admission.Validator so a webhook will be registered for the type.
+// The selector of a MachineSet and the reserved labels of the MachineSet and its template can't be
+// changed once set, and the replicas of control plane Machines can't be changed to 0 or an even count
+// unless the MachineSet is managed by a MachineDeployment.
+func (m *MachineSet) ValidateUpdate(old runtime.Object) error {
+	oldMS, ok := old.(*MachineSet)
+	if !ok {
+		return apierrors.NewBadRequest("expected a MachineSet")
+	}
+
+	specPath := field.NewPath("spec")
+	allErrs := validateSelector(&oldMS.Spec.Selector, &m.Spec.Selector, specPath.Child("selector"))
+	allErrs = append(allErrs, validateReservedLabels(m.Annotations, oldMS.Labels, m.Labels,
+		field.NewPath("metadata", "labels"))...)
+	allErrs = append(allErrs, validateReservedLabels(m.Annotations, oldMS.Spec.Template.Labels, m.Spec.Template.Labels,
+		specPath.Child("template", "metadata", "labels"))...)
+	if metav1.GetControllerOf(m) == nil &&
+		(!apiequality.Semantic.DeepEqual(oldMS.Spec.Replicas, m.Spec.Replicas) || !isControlPlaneTemplate(&oldMS.Spec.Template)) {
+		allErrs = append(allErrs, validateControlPlaneReplicas(m.Spec.Replicas, &m.Spec.Template, specPath.Child("replicas"))...)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(SchemeGroupVersion.WithKind("MachineSet").GroupKind(), m.Name, allErrs)
+}
+
+// validateSelector returns an error if the selector at fldPath is changed once set, as the
+// previously selected Machines would otherwise be orphaned.
+func validateSelector(oldSelector, newSelector *metav1.LabelSelector, fldPath *field.Path) field.ErrorList {
+	if len(oldSelector.MatchLabels)+len(oldSelector.MatchExpressions) == 0 ||
+		apiequality.Semantic.DeepEqual(oldSelector, newSelector) {
+		return nil
+	}
+	return field.ErrorList{
+		field.Forbidden(fldPath, "cannot be changed once set"),
+	}
+}
+
+// validateControlPlaneReplicas returns an error if the template at fldPath creates control plane
+// Machines and replicas is 0 or even, as their etcd members would then lose quorum, or need as
+// many members to keep it as the next odd count while tolerating no more failures.
+func validateControlPlaneReplicas(replicas *int32, template *MachineTemplateSpec, fldPath *field.Path) field.ErrorList {
+	if replicas == nil || !isControlPlaneTemplate(template) {
+		return nil
+	}
+	switch {
+	case *replicas == 0:
+		return field.ErrorList{field.Invalid(fldPath, *replicas, "cannot be 0 for control plane Machines")}
+	case *replicas%2 == 0:
+		return field.ErrorList{field.Invalid(fldPath, *replicas, "must be odd for control plane Machines")}
+	}
+	return nil
+}
+
+// isControlPlaneTemplate returns true if the Machines created from the template run a control plane.
+// It matches util.IsControlPlaneMachineSpec, which can't be imported here as pkg/util imports this package.
+func isControlPlaneTemplate(template *MachineTemplateSpec) bool {
+	return template.Spec.Versions.ControlPlane != ""
+}
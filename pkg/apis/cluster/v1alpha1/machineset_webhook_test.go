@@ -0,0 +1,163 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newControlPlaneMachineSet returns a MachineSet of control plane Machines with the given replicas.
+func newControlPlaneMachineSet(replicas int32) *MachineSet {
+	ms := &MachineSet{Spec: MachineSetSpec{Replicas: &replicas}}
+	ms.Spec.Template.Spec.Versions.ControlPlane = "1.15.3"
+	return ms
+}
+
+// ownedByMachineDeployment sets a MachineDeployment as the controller of the MachineSet.
+func ownedByMachineDeployment(ms *MachineSet) *MachineSet {
+	controller := true
+	ms.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: SchemeGroupVersion.String(),
+		Kind:       "MachineDeployment",
+		Name:       "md",
+		Controller: &controller,
+	}}
+	return ms
+}
+
+func TestMachineSetValidateCreate(t *testing.T) {
+	even := int32(2)
+
+	tests := []struct {
+		name      string
+		ms        *MachineSet
+		expectErr bool
+	}{
+		{
+			name: "control plane with odd replicas",
+			ms:   newControlPlaneMachineSet(3),
+		},
+		{
+			name:      "control plane with zero replicas",
+			ms:        newControlPlaneMachineSet(0),
+			expectErr: true,
+		},
+		{
+			name:      "control plane with even replicas",
+			ms:        newControlPlaneMachineSet(2),
+			expectErr: true,
+		},
+		{
+			name: "control plane of a MachineDeployment with zero replicas",
+			ms:   ownedByMachineDeployment(newControlPlaneMachineSet(0)),
+		},
+		{
+			name: "workers with even replicas",
+			ms:   &MachineSet{Spec: MachineSetSpec{Replicas: &even}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.ms.ValidateCreate()
+			if (err != nil) != tt.expectErr {
+				t.Errorf("expected error: %v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestMachineSetValidateUpdate(t *testing.T) {
+	selector := metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}}
+	otherSelector := metav1.LabelSelector{MatchLabels: map[string]string{"foo": "baz"}}
+
+	tests := []struct {
+		name      string
+		old       *MachineSet
+		new       *MachineSet
+		expectErr bool
+	}{
+		{
+			name: "selector set",
+			old:  &MachineSet{},
+			new:  &MachineSet{Spec: MachineSetSpec{Selector: selector}},
+		},
+		{
+			name:      "selector changed",
+			old:       &MachineSet{Spec: MachineSetSpec{Selector: selector}},
+			new:       &MachineSet{Spec: MachineSetSpec{Selector: otherSelector}},
+			expectErr: true,
+		},
+		{
+			name: "template cluster label unchanged",
+			old: &MachineSet{Spec: MachineSetSpec{Template: MachineTemplateSpec{
+				ObjectMeta: ObjectMeta{Labels: map[string]string{MachineClusterLabelName: "foo"}},
+			}}},
+			new: &MachineSet{Spec: MachineSetSpec{Template: MachineTemplateSpec{
+				ObjectMeta: ObjectMeta{Labels: map[string]string{MachineClusterLabelName: "foo", "bar": "baz"}},
+			}}},
+		},
+		{
+			name: "template cluster label changed",
+			old: &MachineSet{Spec: MachineSetSpec{Template: MachineTemplateSpec{
+				ObjectMeta: ObjectMeta{Labels: map[string]string{MachineClusterLabelName: "foo"}},
+			}}},
+			new: &MachineSet{Spec: MachineSetSpec{Template: MachineTemplateSpec{
+				ObjectMeta: ObjectMeta{Labels: map[string]string{MachineClusterLabelName: "bar"}},
+			}}},
+			expectErr: true,
+		},
+		{
+			name:      "control plane scaled to zero",
+			old:       newControlPlaneMachineSet(3),
+			new:       newControlPlaneMachineSet(0),
+			expectErr: true,
+		},
+		{
+			name:      "control plane scaled to even replicas",
+			old:       newControlPlaneMachineSet(3),
+			new:       newControlPlaneMachineSet(4),
+			expectErr: true,
+		},
+		{
+			name: "control plane scaled to odd replicas",
+			old:  newControlPlaneMachineSet(3),
+			new:  newControlPlaneMachineSet(5),
+		},
+		{
+			name: "control plane with even replicas unchanged",
+			old:  newControlPlaneMachineSet(2),
+			new:  newControlPlaneMachineSet(2),
+		},
+		{
+			name: "control plane of a MachineDeployment scaled to even replicas",
+			old:  ownedByMachineDeployment(newControlPlaneMachineSet(3)),
+			new:  ownedByMachineDeployment(newControlPlaneMachineSet(2)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.new.ValidateUpdate(tt.old)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("expected error: %v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
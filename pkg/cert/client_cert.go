@@ -0,0 +1,93 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cert
+
+import (
+	"crypto"
+	cryptorand "crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/pkg/errors"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+)
+
+// NewClientCertAndKey generates a new private key and a client certificate for the given
+// subject signed by the certificate authority. Both are returned PEM encoded.
+func (ca *CertificateAuthority) NewClientCertAndKey(subject pkix.Name, validity time.Duration) ([]byte, []byte, error) {
+	caCert, caKey, err := ca.parse()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := rsa.GenerateKey(cryptorand.Reader, 2048)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to generate private key")
+	}
+
+	serial, err := cryptorand.Int(cryptorand.Reader, new(big.Int).SetInt64(math.MaxInt64))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to generate serial number")
+	}
+
+	now := time.Now().UTC()
+	tmpl := x509.Certificate{
+		SerialNumber: serial,
+		Subject:      subject,
+		NotBefore:    caCert.NotBefore,
+		NotAfter:     now.Add(validity),
+		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	certDER, err := x509.CreateCertificate(cryptorand.Reader, &tmpl, caCert, key.Public(), caKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to sign client certificate")
+	}
+
+	keyPEM, err := keyutil.MarshalPrivateKeyToPEM(key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to encode private key")
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: certDER}), keyPEM, nil
+}
+
+func (ca *CertificateAuthority) parse() (*x509.Certificate, crypto.Signer, error) {
+	certs, err := certutil.ParseCertsPEM(ca.Certificate)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to parse certificate authority certificate")
+	}
+
+	key, err := keyutil.ParsePrivateKeyPEM(ca.PrivateKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to parse certificate authority private key")
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, nil, errors.New("certificate authority private key is not a signer")
+	}
+
+	return certs[0], signer, nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cert_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+	"sigs.k8s.io/cluster-api/pkg/cert"
+	"sigs.k8s.io/cluster-api/pkg/cert/testutil"
+)
+
+func TestNewClientCertAndKey(t *testing.T) {
+	ca := testutil.NewCertificateAuthority(t)
+	subject := pkix.Name{CommonName: "kubernetes-admin", Organization: []string{"system:masters"}}
+
+	certPEM, keyPEM, err := ca.NewClientCertAndKey(subject, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	certs, err := certutil.ParseCertsPEM(certPEM)
+	if err != nil {
+		t.Fatalf("unable to parse generated certificate: %v", err)
+	}
+	if _, err := keyutil.ParsePrivateKeyPEM(keyPEM); err != nil {
+		t.Fatalf("unable to parse generated key: %v", err)
+	}
+
+	clientCert := certs[0]
+	if clientCert.Subject.CommonName != subject.CommonName {
+		t.Errorf("expected common name %q, got %q", subject.CommonName, clientCert.Subject.CommonName)
+	}
+	if len(clientCert.Subject.Organization) != 1 || clientCert.Subject.Organization[0] != "system:masters" {
+		t.Errorf("expected organization %v, got %v", subject.Organization, clientCert.Subject.Organization)
+	}
+	if clientCert.NotAfter.After(time.Now().Add(time.Hour)) {
+		t.Errorf("expected certificate to expire within an hour, got %v", clientCert.NotAfter)
+	}
+
+	caCerts, _ := certutil.ParseCertsPEM(ca.Certificate)
+	roots := x509.NewCertPool()
+	roots.AddCert(caCerts[0])
+	if _, err := clientCert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}); err != nil {
+		t.Errorf("expected certificate to be signed by the certificate authority, got %v", err)
+	}
+}
+
+func TestNewClientCertAndKeyInvalidAuthority(t *testing.T) {
+	ca := &cert.CertificateAuthority{
+		Certificate: []byte(defaultCertMaterial),
+		PrivateKey:  []byte(defaultKeyMaterial),
+	}
+	if _, _, err := ca.NewClientCertAndKey(pkix.Name{CommonName: "test"}, time.Hour); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
@@ -28,6 +28,8 @@ import (
 type ClusterV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	ClustersGetter
+	ClusterResourceSetsGetter
+	ClusterResourceSetBindingsGetter
 	MachinesGetter
 	MachineClassesGetter
 	MachineDeploymentsGetter
@@ -43,6 +45,14 @@ func (c *ClusterV1alpha1Client) Clusters(namespace string) ClusterInterface {
 	return newClusters(c, namespace)
 }
 
+func (c *ClusterV1alpha1Client) ClusterResourceSets(namespace string) ClusterResourceSetInterface {
+	return newClusterResourceSets(c, namespace)
+}
+
+func (c *ClusterV1alpha1Client) ClusterResourceSetBindings(namespace string) ClusterResourceSetBindingInterface {
+	return newClusterResourceSetBindings(c, namespace)
+}
+
 func (c *ClusterV1alpha1Client) Machines(namespace string) MachineInterface {
 	return newMachines(c, namespace)
 }
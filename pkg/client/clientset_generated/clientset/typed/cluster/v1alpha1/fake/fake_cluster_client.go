@@ -32,6 +32,14 @@ func (c *FakeClusterV1alpha1) Clusters(namespace string) v1alpha1.ClusterInterfa
 	return &FakeClusters{c, namespace}
 }
 
+func (c *FakeClusterV1alpha1) ClusterResourceSets(namespace string) v1alpha1.ClusterResourceSetInterface {
+	return &FakeClusterResourceSets{c, namespace}
+}
+
+func (c *FakeClusterV1alpha1) ClusterResourceSetBindings(namespace string) v1alpha1.ClusterResourceSetBindingInterface {
+	return &FakeClusterResourceSetBindings{c, namespace}
+}
+
 func (c *FakeClusterV1alpha1) Machines(namespace string) v1alpha1.MachineInterface {
 	return &FakeMachines{c, namespace}
 }
@@ -20,6 +20,10 @@ package v1alpha1
 
 type ClusterExpansion interface{}
 
+type ClusterResourceSetExpansion interface{}
+
+type ClusterResourceSetBindingExpansion interface{}
+
 type MachineExpansion interface{}
 
 type MachineClassExpansion interface{}
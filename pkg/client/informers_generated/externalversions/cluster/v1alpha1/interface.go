@@ -26,6 +26,10 @@ import (
 type Interface interface {
 	// Clusters returns a ClusterInformer.
 	Clusters() ClusterInformer
+	// ClusterResourceSets returns a ClusterResourceSetInformer.
+	ClusterResourceSets() ClusterResourceSetInformer
+	// ClusterResourceSetBindings returns a ClusterResourceSetBindingInformer.
+	ClusterResourceSetBindings() ClusterResourceSetBindingInformer
 	// Machines returns a MachineInformer.
 	Machines() MachineInformer
 	// MachineClasses returns a MachineClassInformer.
@@ -52,6 +56,16 @@ func (v *version) Clusters() ClusterInformer {
 	return &clusterInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// ClusterResourceSets returns a ClusterResourceSetInformer.
+func (v *version) ClusterResourceSets() ClusterResourceSetInformer {
+	return &clusterResourceSetInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// ClusterResourceSetBindings returns a ClusterResourceSetBindingInformer.
+func (v *version) ClusterResourceSetBindings() ClusterResourceSetBindingInformer {
+	return &clusterResourceSetBindingInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Machines returns a MachineInformer.
 func (v *version) Machines() MachineInformer {
 	return &machineInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
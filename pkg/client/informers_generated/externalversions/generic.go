@@ -55,6 +55,10 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=cluster.k8s.io, Version=v1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("clusters"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Cluster().V1alpha1().Clusters().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("clusterresourcesets"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Cluster().V1alpha1().ClusterResourceSets().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("clusterresourcesetbindings"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Cluster().V1alpha1().ClusterResourceSetBindings().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("machines"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Cluster().V1alpha1().Machines().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("machineclasses"):
@@ -26,6 +26,22 @@ type ClusterListerExpansion interface{}
 // ClusterNamespaceLister.
 type ClusterNamespaceListerExpansion interface{}
 
+// ClusterResourceSetListerExpansion allows custom methods to be added to
+// ClusterResourceSetLister.
+type ClusterResourceSetListerExpansion interface{}
+
+// ClusterResourceSetNamespaceListerExpansion allows custom methods to be added to
+// ClusterResourceSetNamespaceLister.
+type ClusterResourceSetNamespaceListerExpansion interface{}
+
+// ClusterResourceSetBindingListerExpansion allows custom methods to be added to
+// ClusterResourceSetBindingLister.
+type ClusterResourceSetBindingListerExpansion interface{}
+
+// ClusterResourceSetBindingNamespaceListerExpansion allows custom methods to be added to
+// ClusterResourceSetBindingNamespaceLister.
+type ClusterResourceSetBindingNamespaceListerExpansion interface{}
+
 // MachineListerExpansion allows custom methods to be added to
 // MachineLister.
 type MachineListerExpansion interface{}
@@ -0,0 +1,307 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addons
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/controller/predicates"
+	"sigs.k8s.io/cluster-api/pkg/controller/remote"
+	"sigs.k8s.io/cluster-api/pkg/tracing"
+	"sigs.k8s.io/cluster-api/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// controllerName is the name of this controller
+const controllerName = "addons_controller"
+
+const (
+	// kubeProxyName is the name of the kube-proxy daemonset and of its container.
+	kubeProxyName = "kube-proxy"
+
+	// coreDNSName is the name of the CoreDNS deployment, configmap and container.
+	coreDNSName = "coredns"
+
+	// corefileKey is the key of the Corefile in the CoreDNS configmap.
+	corefileKey = "Corefile"
+)
+
+var log = logf.Log.WithName("addons-controller")
+
+// coreDNSVersions maps Kubernetes minor versions to the CoreDNS version deployed by kubeadm.
+var coreDNSVersions = map[string]string{
+	"1.11": "1.1.3",
+	"1.12": "1.2.2",
+	"1.13": "1.2.6",
+	"1.14": "1.3.1",
+	"1.15": "1.3.1",
+	"1.16": "1.6.2",
+}
+
+// Add creates a new Addons Controller and adds it to the Manager with default RBAC.
+// The Manager will set fields on the Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	tracker, err := remote.ClusterCacheTrackerFor(mgr)
+	if err != nil {
+		return err
+	}
+	r := newReconciler(mgr, tracker)
+	return add(mgr, r, r.MachineToCluster)
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, tracker *remote.ClusterCacheTracker) *ReconcileAddons {
+	return &ReconcileAddons{
+		Client:       mgr.GetClient(),
+		scheme:       mgr.GetScheme(),
+		recorder:     mgr.GetEventRecorderFor(controllerName),
+		remoteClient: tracker.Client,
+	}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler, mapFn handler.ToRequestsFunc) error {
+	// Create a new controller
+	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to Clusters.
+	if err := c.Watch(&source.Kind{Type: &v1alpha1.Cluster{}}, &handler.EnqueueRequestForObject{}, predicates.WatchFilter()); err != nil {
+		return err
+	}
+
+	// Watch for changes to control plane Machines and reconcile their Cluster.
+	return c.Watch(&source.Kind{Type: &v1alpha1.Machine{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: mapFn}, predicates.WatchFilter())
+}
+
+var _ reconcile.Reconciler = &ReconcileAddons{}
+
+// ReconcileAddons reconciles a Cluster object to keep the core addons of the
+// workload cluster in line with its control plane version.
+type ReconcileAddons struct {
+	client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+
+	// remoteClient returns a client for the workload cluster.
+	remoteClient func(cluster *v1alpha1.Cluster) (client.Client, error)
+}
+
+// Reconcile upgrades the kube-proxy daemonset and the CoreDNS deployment of a Cluster
+// once all of its control plane Machines run the same Kubernetes version.
+func (r *ReconcileAddons) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	ctx, span := tracing.StartReconcile(context.Background(), controllerName, request)
+	defer span.End()
+
+	// Fetch the Cluster instance.
+	cluster := &v1alpha1.Cluster{}
+	if err := r.Get(ctx, request.NamespacedName, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if !cluster.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+
+	var kubeProxy, coreDNS v1alpha1.AddonUpgrade
+	if cluster.Spec.Addons != nil && cluster.Spec.Addons.KubeProxy != nil {
+		kubeProxy = *cluster.Spec.Addons.KubeProxy
+	}
+	if cluster.Spec.Addons != nil && cluster.Spec.Addons.CoreDNS != nil {
+		coreDNS = *cluster.Spec.Addons.CoreDNS
+	}
+	if kubeProxy.Skip && coreDNS.Skip {
+		return reconcile.Result{}, nil
+	}
+
+	machines := &v1alpha1.MachineList{}
+	if err := r.List(ctx, machines, client.InNamespace(cluster.Namespace),
+		client.MatchingLabels(map[string]string{v1alpha1.MachineClusterLabelName: cluster.Name})); err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "failed to list Machines for Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+
+	version := controlPlaneVersion(machines.Items)
+	if version == "" {
+		log.V(2).Info("Control plane doesn't run a single version, won't reconcile addons", "cluster", cluster.Name, "namespace", cluster.Namespace)
+		return reconcile.Result{}, nil
+	}
+
+	if _, err := remote.GetKubeConfigSecret(r.Client, cluster.Name, cluster.Namespace); err != nil {
+		if err == remote.ErrSecretNotFound {
+			log.V(2).Info("Cluster doesn't have a kubeconfig secret yet, won't reconcile addons", "cluster", cluster.Name, "namespace", cluster.Namespace)
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	c, err := r.remoteClient(cluster)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if !kubeProxy.Skip {
+		tag := kubeProxy.ImageTag
+		if tag == "" {
+			tag = "v" + version
+		}
+		if err := r.upgradeKubeProxy(ctx, c, cluster, tag); err != nil {
+			r.recorder.Eventf(cluster, corev1.EventTypeWarning, "FailedUpgradeKubeProxy", "%v", err)
+			return reconcile.Result{}, err
+		}
+	}
+
+	if !coreDNS.Skip {
+		tag := coreDNS.ImageTag
+		if tag == "" {
+			tag = coreDNSVersions[minorVersion(version)]
+		}
+		if tag == "" {
+			log.V(2).Info("No known CoreDNS version for Kubernetes version, won't upgrade CoreDNS", "version", version, "cluster", cluster.Name, "namespace", cluster.Namespace)
+			return reconcile.Result{}, nil
+		}
+		if err := r.upgradeCoreDNS(ctx, c, cluster, tag, coreDNS.ImageTag != ""); err != nil {
+			r.recorder.Eventf(cluster, corev1.EventTypeWarning, "FailedUpgradeCoreDNS", "%v", err)
+			return reconcile.Result{}, err
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// upgradeKubeProxy sets the image tag of the kube-proxy daemonset.
+func (r *ReconcileAddons) upgradeKubeProxy(ctx context.Context, c client.Client, cluster *v1alpha1.Cluster, tag string) error {
+	ds := &appsv1.DaemonSet{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: kubeProxyName}, ds); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrap(err, "failed to get kube-proxy daemonset")
+	}
+
+	if !setContainerImageTag(ds.Spec.Template.Spec.Containers, kubeProxyName, tag) {
+		return nil
+	}
+
+	if err := c.Update(ctx, ds); err != nil {
+		return errors.Wrap(err, "failed to update kube-proxy daemonset")
+	}
+
+	log.Info("Upgraded kube-proxy", "cluster", cluster.Name, "namespace", cluster.Namespace, "version", tag)
+	r.recorder.Eventf(cluster, corev1.EventTypeNormal, "SuccessfulUpgradeKubeProxy", "Upgraded kube-proxy to %s", tag)
+	return nil
+}
+
+// upgradeCoreDNS sets the image tag of the CoreDNS deployment, migrating its Corefile first.
+// CoreDNS is never downgraded unless the tag is pinned.
+func (r *ReconcileAddons) upgradeCoreDNS(ctx context.Context, c client.Client, cluster *v1alpha1.Cluster, tag string, pinned bool) error {
+	deployment := &appsv1.Deployment{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: coreDNSName}, deployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrap(err, "failed to get CoreDNS deployment")
+	}
+
+	current := containerImageTag(deployment.Spec.Template.Spec.Containers, coreDNSName)
+	if current == tag || (!pinned && !versionLess(current, tag)) {
+		return nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: coreDNSName}, configMap); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to get CoreDNS configmap")
+	} else if err == nil {
+		if corefile, ok := configMap.Data[corefileKey]; ok {
+			if migrated := migrateCorefile(corefile, current, tag); migrated != corefile {
+				configMap.Data[corefileKey] = migrated
+				if err := c.Update(ctx, configMap); err != nil {
+					return errors.Wrap(err, "failed to update CoreDNS configmap")
+				}
+			}
+		}
+	}
+
+	setContainerImageTag(deployment.Spec.Template.Spec.Containers, coreDNSName, tag)
+	if err := c.Update(ctx, deployment); err != nil {
+		return errors.Wrap(err, "failed to update CoreDNS deployment")
+	}
+
+	log.Info("Upgraded CoreDNS", "cluster", cluster.Name, "namespace", cluster.Namespace, "from", current, "to", tag)
+	r.recorder.Eventf(cluster, corev1.EventTypeNormal, "SuccessfulUpgradeCoreDNS", "Upgraded CoreDNS to %s", tag)
+	return nil
+}
+
+// MachineToCluster is a handler.ToRequestsFunc to be used to enqueue requests for reconciliation
+// of the Cluster of a control plane Machine.
+func (r *ReconcileAddons) MachineToCluster(o handler.MapObject) []reconcile.Request {
+	m, ok := o.Object.(*v1alpha1.Machine)
+	if !ok || !util.IsControlPlaneMachine(m) || m.Labels[v1alpha1.MachineClusterLabelName] == "" {
+		return nil
+	}
+
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{Namespace: m.Namespace, Name: m.Labels[v1alpha1.MachineClusterLabelName]},
+	}}
+}
+
+// controlPlaneVersion returns the Kubernetes version run by all control plane machines,
+// or an empty string if there are none or they don't agree.
+func controlPlaneVersion(machines []v1alpha1.Machine) string {
+	version := ""
+	for i := range machines {
+		m := &machines[i]
+		if !util.IsControlPlaneMachine(m) {
+			continue
+		}
+		if !m.DeletionTimestamp.IsZero() {
+			return ""
+		}
+
+		current := m.Spec.Versions.ControlPlane
+		if m.Status.Versions != nil {
+			current = m.Status.Versions.ControlPlane
+		}
+		current = strings.TrimPrefix(current, "v")
+
+		if current == "" || (version != "" && version != current) {
+			return ""
+		}
+		version = current
+	}
+	return version
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addons
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/controller/remote"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const corefile = `.:53 {
+    errors
+    kubernetes cluster.local in-addr.arpa ip6.arpa {
+       pods insecure
+       upstream
+       fallthrough in-addr.arpa ip6.arpa
+    }
+    proxy . /etc/resolv.conf
+    cache 30
+}`
+
+func TestReconcile(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+
+	testCases := []struct {
+		name              string
+		addons            *v1alpha1.ClusterAddons
+		versions          []string
+		expectKubeProxy   string
+		expectCoreDNS     string
+		expectCorefileMod bool
+	}{
+		{
+			name:              "control plane upgraded",
+			versions:          []string{"1.16.2", "v1.16.2"},
+			expectKubeProxy:   "k8s.gcr.io/kube-proxy:v1.16.2",
+			expectCoreDNS:     "k8s.gcr.io/coredns:1.6.2",
+			expectCorefileMod: true,
+		},
+		{
+			name:            "control plane upgrade in progress",
+			versions:        []string{"1.16.2", "1.15.3"},
+			expectKubeProxy: "k8s.gcr.io/kube-proxy:v1.15.3",
+			expectCoreDNS:   "k8s.gcr.io/coredns:1.3.1",
+		},
+		{
+			name:            "upgrades skipped",
+			addons:          &v1alpha1.ClusterAddons{KubeProxy: &v1alpha1.AddonUpgrade{Skip: true}, CoreDNS: &v1alpha1.AddonUpgrade{Skip: true}},
+			versions:        []string{"1.16.2"},
+			expectKubeProxy: "k8s.gcr.io/kube-proxy:v1.15.3",
+			expectCoreDNS:   "k8s.gcr.io/coredns:1.3.1",
+		},
+		{
+			name:            "image tags pinned",
+			addons:          &v1alpha1.ClusterAddons{KubeProxy: &v1alpha1.AddonUpgrade{ImageTag: "v1.16.0"}, CoreDNS: &v1alpha1.AddonUpgrade{ImageTag: "1.5.2"}},
+			versions:        []string{"1.16.2"},
+			expectKubeProxy: "k8s.gcr.io/kube-proxy:v1.16.0",
+			expectCoreDNS:   "k8s.gcr.io/coredns:1.5.2",
+		},
+		{
+			name:            "CoreDNS is not downgraded",
+			versions:        []string{"1.13.5"},
+			expectKubeProxy: "k8s.gcr.io/kube-proxy:v1.13.5",
+			expectCoreDNS:   "k8s.gcr.io/coredns:1.3.1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := &v1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec:       v1alpha1.ClusterSpec{Addons: tc.addons},
+			}
+			kubeconfigSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: remote.KubeConfigSecretName("test"), Namespace: "default"},
+				Data:       map[string][]byte{remote.KubeConfigSecretKey: []byte("kubeconfig")},
+			}
+			objs := []runtime.Object{cluster, kubeconfigSecret}
+			for i, version := range tc.versions {
+				objs = append(objs, &v1alpha1.Machine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      fmt.Sprintf("control-plane-%d", i),
+						Namespace: "default",
+						Labels:    map[string]string{v1alpha1.MachineClusterLabelName: "test"},
+					},
+					Spec: v1alpha1.MachineSpec{
+						Versions: v1alpha1.MachineVersionInfo{ControlPlane: version, Kubelet: version},
+					},
+				})
+			}
+
+			workload := fake.NewFakeClientWithScheme(scheme.Scheme, newKubeProxy(), newCoreDNS(), newCorefile())
+			r := &ReconcileAddons{
+				Client:   fake.NewFakeClientWithScheme(scheme.Scheme, objs...),
+				scheme:   scheme.Scheme,
+				recorder: record.NewFakeRecorder(32),
+				remoteClient: func(*v1alpha1.Cluster) (client.Client, error) {
+					return workload, nil
+				},
+			}
+
+			key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+			if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			ds := &appsv1.DaemonSet{}
+			if err := workload.Get(context.Background(), client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: kubeProxyName}, ds); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if image := ds.Spec.Template.Spec.Containers[0].Image; image != tc.expectKubeProxy {
+				t.Fatalf("Expected kube-proxy image %q, got %q", tc.expectKubeProxy, image)
+			}
+
+			deployment := &appsv1.Deployment{}
+			if err := workload.Get(context.Background(), client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: coreDNSName}, deployment); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if image := deployment.Spec.Template.Spec.Containers[0].Image; image != tc.expectCoreDNS {
+				t.Fatalf("Expected CoreDNS image %q, got %q", tc.expectCoreDNS, image)
+			}
+
+			configMap := &corev1.ConfigMap{}
+			if err := workload.Get(context.Background(), client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: coreDNSName}, configMap); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if modified := configMap.Data[corefileKey] != corefile; modified != tc.expectCorefileMod {
+				t.Fatalf("Expected Corefile to be migrated: %v, got %v", tc.expectCorefileMod, modified)
+			}
+		})
+	}
+}
+
+func TestMachineToCluster(t *testing.T) {
+	r := &ReconcileAddons{}
+
+	controlPlane := &v1alpha1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "control-plane",
+			Namespace: "default",
+			Labels:    map[string]string{v1alpha1.MachineClusterLabelName: "test"},
+		},
+		Spec: v1alpha1.MachineSpec{Versions: v1alpha1.MachineVersionInfo{ControlPlane: "1.15.3"}},
+	}
+	if requests := r.MachineToCluster(handler.MapObject{Meta: controlPlane, Object: controlPlane}); len(requests) != 1 || requests[0].Name != "test" {
+		t.Fatalf("Expected a request for Cluster %q, got %v", "test", requests)
+	}
+
+	worker := controlPlane.DeepCopy()
+	worker.Spec.Versions.ControlPlane = ""
+	if requests := r.MachineToCluster(handler.MapObject{Meta: worker, Object: worker}); len(requests) != 0 {
+		t.Fatalf("Expected no requests, got %v", requests)
+	}
+}
+
+func newKubeProxy() *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: kubeProxyName, Namespace: metav1.NamespaceSystem},
+		Spec: appsv1.DaemonSetSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: kubeProxyName, Image: "k8s.gcr.io/kube-proxy:v1.15.3"}},
+				},
+			},
+		},
+	}
+}
+
+func newCoreDNS() *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: coreDNSName, Namespace: metav1.NamespaceSystem},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: coreDNSName, Image: "k8s.gcr.io/coredns:1.3.1"}},
+				},
+			},
+		},
+	}
+}
+
+func newCorefile() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: coreDNSName, Namespace: metav1.NamespaceSystem},
+		Data:       map[string]string{corefileKey: corefile},
+	}
+}
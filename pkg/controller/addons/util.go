@@ -0,0 +1,116 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addons
+
+import (
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// containerImageTag returns the image tag of the named container.
+func containerImageTag(containers []corev1.Container, name string) string {
+	for _, c := range containers {
+		if c.Name == name {
+			_, tag := splitImage(c.Image)
+			return tag
+		}
+	}
+	return ""
+}
+
+// setContainerImageTag sets the image tag of the named container and reports whether it changed.
+func setContainerImageTag(containers []corev1.Container, name, tag string) bool {
+	for i := range containers {
+		if containers[i].Name != name {
+			continue
+		}
+		repository, current := splitImage(containers[i].Image)
+		if current == tag {
+			return false
+		}
+		containers[i].Image = repository + ":" + tag
+		return true
+	}
+	return false
+}
+
+// splitImage splits an image reference into its repository and tag.
+func splitImage(image string) (string, string) {
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return image, ""
+	}
+	return image[:i], image[i+1:]
+}
+
+// minorVersion returns the major.minor part of a version.
+func minorVersion(version string) string {
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// versionLess reports whether version a is lower than version b. Pre-release
+// and build metadata are ignored, and unparsable components compare as zero.
+func versionLess(a, b string) bool {
+	pa, pb := parseVersion(a), parseVersion(b)
+	for i := range pa {
+		if pa[i] != pb[i] {
+			return pa[i] < pb[i]
+		}
+	}
+	return false
+}
+
+func parseVersion(version string) [3]int {
+	var parsed [3]int
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	for i, part := range strings.SplitN(version, ".", 3) {
+		parsed[i], _ = strconv.Atoi(part)
+	}
+	return parsed
+}
+
+// migrateCorefile rewrites the plugins of a Corefile that were removed between
+// the from and to CoreDNS versions.
+func migrateCorefile(corefile, from, to string) string {
+	// The proxy plugin and the upstream option of the kubernetes plugin were removed in 1.6.0.
+	if !versionLess(from, "1.6.0") || versionLess(to, "1.6.0") {
+		return corefile
+	}
+
+	lines := strings.Split(corefile, "\n")
+	migrated := make([]string, 0, len(lines))
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "upstream" || strings.HasPrefix(trimmed, "upstream "):
+			continue
+		case strings.HasPrefix(trimmed, "proxy "):
+			line = strings.Replace(line, "proxy ", "forward ", 1)
+		}
+		migrated = append(migrated, line)
+	}
+	return strings.Join(migrated, "\n")
+}
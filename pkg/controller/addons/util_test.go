@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addons
+
+import (
+	"testing"
+)
+
+func TestSplitImage(t *testing.T) {
+	testCases := []struct {
+		image      string
+		repository string
+		tag        string
+	}{
+		{image: "k8s.gcr.io/kube-proxy:v1.15.3", repository: "k8s.gcr.io/kube-proxy", tag: "v1.15.3"},
+		{image: "registry:5000/coredns:1.3.1", repository: "registry:5000/coredns", tag: "1.3.1"},
+		{image: "registry:5000/coredns", repository: "registry:5000/coredns"},
+		{image: "coredns", repository: "coredns"},
+	}
+
+	for _, tc := range testCases {
+		repository, tag := splitImage(tc.image)
+		if repository != tc.repository || tag != tc.tag {
+			t.Errorf("splitImage(%q) = %q, %q, expected %q, %q", tc.image, repository, tag, tc.repository, tc.tag)
+		}
+	}
+}
+
+func TestVersionLess(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected bool
+	}{
+		{a: "1.3.1", b: "1.6.2", expected: true},
+		{a: "v1.15.3", b: "1.15.10", expected: true},
+		{a: "1.6.2", b: "1.6.2"},
+		{a: "1.6.2", b: "1.3.1"},
+		{a: "1.6.2-rc.1", b: "1.6.2"},
+	}
+
+	for _, tc := range testCases {
+		if actual := versionLess(tc.a, tc.b); actual != tc.expected {
+			t.Errorf("versionLess(%q, %q) = %v, expected %v", tc.a, tc.b, actual, tc.expected)
+		}
+	}
+}
+
+func TestMigrateCorefile(t *testing.T) {
+	expected := `.:53 {
+    errors
+    kubernetes cluster.local in-addr.arpa ip6.arpa {
+       pods insecure
+       fallthrough in-addr.arpa ip6.arpa
+    }
+    forward . /etc/resolv.conf
+    cache 30
+}`
+
+	if migrated := migrateCorefile(corefile, "1.3.1", "1.6.2"); migrated != expected {
+		t.Errorf("Expected migrated Corefile:\n%s\ngot:\n%s", expected, migrated)
+	}
+	if migrated := migrateCorefile(corefile, "1.2.6", "1.3.1"); migrated != corefile {
+		t.Errorf("Expected Corefile to be left untouched, got:\n%s", migrated)
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certexpiry
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/controller/predicates"
+	"sigs.k8s.io/cluster-api/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// controllerName is the name of this controller
+const controllerName = "certexpiry_controller"
+
+const (
+	// checkInterval is the amount of time between two checks of the same Machine.
+	checkInterval = 12 * time.Hour
+
+	// dialTimeout is the amount of time allowed to connect to an API server.
+	dialTimeout = 10 * time.Second
+)
+
+var log = logf.Log.WithName("certexpiry-controller")
+
+// RenewalThreshold is how long before expiry the renewal of a control plane
+// Machine's certificates is requested.
+var RenewalThreshold = 30 * 24 * time.Hour
+
+// APIServerPort is the port the API server listens on, on control plane Machines.
+var APIServerPort = 6443
+
+// Add creates a new certificate expiry Controller and adds it to the Manager with default RBAC.
+// The Manager will set fields on the Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileCertificateExpiry{
+		Client:    mgr.GetClient(),
+		scheme:    mgr.GetScheme(),
+		recorder:  mgr.GetEventRecorderFor(controllerName),
+		getExpiry: servingCertificateExpiry,
+	}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to Machines.
+	return c.Watch(&source.Kind{Type: &v1alpha1.Machine{}}, &handler.EnqueueRequestForObject{}, predicates.WatchFilter())
+}
+
+var _ reconcile.Reconciler = &ReconcileCertificateExpiry{}
+
+// ReconcileCertificateExpiry reconciles control plane Machines to track the expiry of their certificates.
+type ReconcileCertificateExpiry struct {
+	client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+
+	// getExpiry returns the expiry of the certificate served at the given address.
+	getExpiry func(address string) (time.Time, error)
+}
+
+// Reconcile records the certificate expiry date of a control plane Machine, and the number of days
+// remaining before it, and requests
+// the renewal of its certificates when they are about to expire.
+func (r *ReconcileCertificateExpiry) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	ctx := context.Background()
+
+	// Fetch the Machine instance.
+	machine := &v1alpha1.Machine{}
+	if err := r.Get(ctx, request.NamespacedName, machine); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if !machine.DeletionTimestamp.IsZero() || !util.IsControlPlaneMachine(machine) {
+		return reconcile.Result{}, nil
+	}
+
+	address := apiServerAddress(machine)
+	if address == "" {
+		log.V(2).Info("Machine doesn't have an address yet, retrying later", "machine", machine.Name, "namespace", machine.Namespace)
+		return reconcile.Result{RequeueAfter: time.Minute}, nil
+	}
+
+	expiry, err := r.getExpiry(address)
+	if err != nil {
+		log.Error(err, "Failed to get certificate expiry", "machine", machine.Name, "namespace", machine.Namespace)
+		return reconcile.Result{}, err
+	}
+
+	remaining := time.Until(expiry)
+	days := int32(remaining.Hours() / 24)
+	if machine.Status.CertificatesExpiryDate == nil || !machine.Status.CertificatesExpiryDate.Time.Equal(expiry) ||
+		machine.Status.CertificatesDaysRemaining == nil || *machine.Status.CertificatesDaysRemaining != days {
+		machine.Status.CertificatesExpiryDate = &metav1.Time{Time: expiry}
+		machine.Status.CertificatesDaysRemaining = &days
+		if err := r.Status().Update(ctx, machine); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if remaining > RenewalThreshold {
+		return reconcile.Result{RequeueAfter: checkInterval}, nil
+	}
+
+	r.recorder.Eventf(machine, corev1.EventTypeWarning, "CertificatesExpiringSoon",
+		"Control plane certificates expire in %d days", days)
+
+	if _, ok := machine.Annotations[v1alpha1.MachineRenewCertificatesAnnotation]; !ok {
+		if machine.Annotations == nil {
+			machine.Annotations = map[string]string{}
+		}
+		machine.Annotations[v1alpha1.MachineRenewCertificatesAnnotation] = time.Now().UTC().Format(time.RFC3339)
+		if err := r.Update(ctx, machine); err != nil {
+			return reconcile.Result{}, err
+		}
+		log.Info("Requested certificate renewal", "machine", machine.Name, "namespace", machine.Namespace)
+	}
+
+	return reconcile.Result{RequeueAfter: checkInterval}, nil
+}
+
+// apiServerAddress returns the address of the API server running on the machine. Each control plane
+// machine serves its own certificate, so the machine address and the port of the API server are used
+// rather than the cluster endpoint, which may be a load balancer listening on another port.
+func apiServerAddress(machine *v1alpha1.Machine) string {
+	for _, addressType := range []corev1.NodeAddressType{corev1.NodeInternalIP, corev1.NodeExternalIP, corev1.NodeHostName} {
+		for _, address := range machine.Status.Addresses {
+			if address.Type == addressType && address.Address != "" {
+				return net.JoinHostPort(address.Address, strconv.Itoa(APIServerPort))
+			}
+		}
+	}
+
+	return ""
+}
+
+// servingCertificateExpiry connects to address and returns the expiry of the certificate it serves.
+func servingCertificateExpiry(address string) (time.Time, error) {
+	// The certificate is only inspected, not trusted, so there is no need to verify it.
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: dialTimeout}, "tcp", address, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to connect to %q", address)
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return time.Time{}, errors.Errorf("no certificate served at %q", address)
+	}
+
+	return certs[0].NotAfter, nil
+}
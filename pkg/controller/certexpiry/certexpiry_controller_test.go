@@ -0,0 +1,205 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certexpiry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newControlPlaneMachine(name string) *v1alpha1.Machine {
+	return &v1alpha1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels: map[string]string{
+				v1alpha1.MachineClusterLabelName: "test-cluster",
+			},
+		},
+		Spec: v1alpha1.MachineSpec{
+			Versions: v1alpha1.MachineVersionInfo{ControlPlane: "1.14.3"},
+		},
+		Status: v1alpha1.MachineStatus{
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeExternalIP, Address: "1.2.3.4"},
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+			},
+		},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+		Status: v1alpha1.ClusterStatus{
+			APIEndpoints: []v1alpha1.APIEndpoint{{Host: "lb", Port: 443}},
+		},
+	}
+
+	testCases := []struct {
+		name              string
+		expiresIn         time.Duration
+		expectDays        int32
+		expectAnnotation  bool
+		expectEventsCount int
+	}{
+		{
+			name:       "certificates far from expiry",
+			expiresIn:  200*24*time.Hour + time.Hour,
+			expectDays: 200,
+		},
+		{
+			name:              "certificates about to expire",
+			expiresIn:         10*24*time.Hour + time.Hour,
+			expectDays:        10,
+			expectAnnotation:  true,
+			expectEventsCount: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			machine := newControlPlaneMachine("cp-0")
+			expiry := time.Now().Add(tc.expiresIn).Truncate(time.Second)
+			recorder := record.NewFakeRecorder(32)
+
+			var dialed string
+			r := &ReconcileCertificateExpiry{
+				Client:   fake.NewFakeClientWithScheme(scheme.Scheme, cluster.DeepCopy(), machine),
+				scheme:   scheme.Scheme,
+				recorder: recorder,
+				getExpiry: func(address string) (time.Time, error) {
+					dialed = address
+					return expiry, nil
+				},
+			}
+
+			key := types.NamespacedName{Name: machine.Name, Namespace: machine.Namespace}
+			result, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if result.RequeueAfter != checkInterval {
+				t.Fatalf("Expected requeue after %v, got %v", checkInterval, result.RequeueAfter)
+			}
+			// The API server of the machine is checked, not the load balancer of the cluster.
+			if dialed != "10.0.0.1:6443" {
+				t.Fatalf("Expected API server address %q, got %q", "10.0.0.1:6443", dialed)
+			}
+
+			updated := &v1alpha1.Machine{}
+			if err := r.Get(context.Background(), key, updated); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if updated.Status.CertificatesExpiryDate == nil || !updated.Status.CertificatesExpiryDate.Time.Equal(expiry) {
+				t.Fatalf("Expected certificates expiry date %v, got %v", expiry, updated.Status.CertificatesExpiryDate)
+			}
+			if updated.Status.CertificatesDaysRemaining == nil || *updated.Status.CertificatesDaysRemaining != tc.expectDays {
+				t.Fatalf("Expected %d certificates days remaining, got %v", tc.expectDays, updated.Status.CertificatesDaysRemaining)
+			}
+			if _, ok := updated.Annotations[v1alpha1.MachineRenewCertificatesAnnotation]; ok != tc.expectAnnotation {
+				t.Fatalf("Expected renew certificates annotation to be set: %v, got annotations %v", tc.expectAnnotation, updated.Annotations)
+			}
+			if len(recorder.Events) != tc.expectEventsCount {
+				t.Fatalf("Expected %d events, got %d", tc.expectEventsCount, len(recorder.Events))
+			}
+		})
+	}
+}
+
+func TestReconcileIgnoresWorkerMachines(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+
+	machine := newControlPlaneMachine("worker-0")
+	machine.Spec.Versions.ControlPlane = ""
+
+	r := &ReconcileCertificateExpiry{
+		Client:   fake.NewFakeClientWithScheme(scheme.Scheme, machine),
+		scheme:   scheme.Scheme,
+		recorder: record.NewFakeRecorder(32),
+		getExpiry: func(address string) (time.Time, error) {
+			t.Fatalf("Unexpected certificate check for worker machine at %q", address)
+			return time.Time{}, nil
+		},
+	}
+
+	key := types.NamespacedName{Name: machine.Name, Namespace: machine.Namespace}
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestAPIServerAddress(t *testing.T) {
+	defer func(port int) { APIServerPort = port }(APIServerPort)
+
+	testCases := []struct {
+		name     string
+		port     int
+		machine  *v1alpha1.Machine
+		expected string
+	}{
+		{
+			name:     "internal address",
+			port:     6443,
+			machine:  newControlPlaneMachine("cp-0"),
+			expected: "10.0.0.1:6443",
+		},
+		{
+			name:     "configured port",
+			port:     8443,
+			machine:  newControlPlaneMachine("cp-0"),
+			expected: "10.0.0.1:8443",
+		},
+		{
+			name:     "no addresses",
+			port:     6443,
+			machine:  &v1alpha1.Machine{},
+			expected: "",
+		},
+		{
+			name: "external address only",
+			port: 6443,
+			machine: &v1alpha1.Machine{
+				Status: v1alpha1.MachineStatus{
+					Addresses: []corev1.NodeAddress{{Type: corev1.NodeExternalIP, Address: "1.2.3.4"}},
+				},
+			},
+			expected: "1.2.3.4:6443",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			APIServerPort = tc.port
+			if address := apiServerAddress(tc.machine); address != tc.expected {
+				t.Fatalf("Expected address %q, got %q", tc.expected, address)
+			}
+		})
+	}
+}
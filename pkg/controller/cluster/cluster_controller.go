@@ -18,50 +18,88 @@ package cluster
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/klog"
+	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
 	clusterv1alpha1 "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
 	controllerError "sigs.k8s.io/cluster-api/pkg/controller/error"
+	"sigs.k8s.io/cluster-api/pkg/controller/predicates"
+	"sigs.k8s.io/cluster-api/pkg/tracing"
 	"sigs.k8s.io/cluster-api/pkg/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
-var DefaultActuator Actuator
+const (
+	// controllerName is the name of this controller
+	controllerName = "cluster_controller"
+
+	// apiEndpointRequeueAfter is how long to wait before checking invalid API endpoints again.
+	apiEndpointRequeueAfter = 30 * time.Second
+
+	// deletionRequeueAfter is how long to wait before checking the Machines of a deleted Cluster again.
+	deletionRequeueAfter = 10 * time.Second
+)
+
+var (
+	DefaultActuator Actuator
+
+	// MaxConcurrentReconciles is the number of Clusters reconciled in parallel.
+	MaxConcurrentReconciles = 1
+
+	// DeletionPhaseTimeout is how long the deletion of the workers or of the control plane of a
+	// Cluster can take before the next phase of the deletion starts anyway, possibly leaking the
+	// infrastructure of the remaining Machines. Zero means no timeout.
+	DeletionPhaseTimeout time.Duration
+
+	log = logf.Log.WithName("cluster-controller")
+)
 
 func AddWithActuator(mgr manager.Manager, actuator Actuator) error {
-	return add(mgr, newReconciler(mgr, actuator))
+	r := newReconciler(mgr, actuator)
+	return add(mgr, r, r.MachineToCluster)
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager, actuator Actuator) reconcile.Reconciler {
-	return &ReconcileCluster{Client: mgr.GetClient(), scheme: mgr.GetScheme(), actuator: actuator}
+func newReconciler(mgr manager.Manager, actuator Actuator) *ReconcileCluster {
+	return &ReconcileCluster{
+		Client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: mgr.GetEventRecorderFor(controllerName),
+		actuator: actuator,
+	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
-func add(mgr manager.Manager, r reconcile.Reconciler) error {
+func add(mgr manager.Manager, r reconcile.Reconciler, mapFn handler.ToRequestsFunc) error {
 	// Create a new controller
-	c, err := controller.New("cluster_controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New(controllerName, mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: MaxConcurrentReconciles,
+	})
 	if err != nil {
 		return err
 	}
 
 	// Watch for changes to Cluster
-	err = c.Watch(&source.Kind{Type: &clusterv1alpha1.Cluster{}}, &handler.EnqueueRequestForObject{})
+	err = c.Watch(&source.Kind{Type: &clusterv1alpha1.Cluster{}}, &handler.EnqueueRequestForObject{}, predicates.WatchFilter())
 	if err != nil {
 		return err
 	}
 
-	return nil
+	// Watch for changes to control plane Machines and reconcile their Cluster.
+	return c.Watch(&source.Kind{Type: &clusterv1alpha1.Machine{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: mapFn}, predicates.WatchFilter())
 }
 
 var _ reconcile.Reconciler = &ReconcileCluster{}
@@ -70,12 +108,17 @@ var _ reconcile.Reconciler = &ReconcileCluster{}
 type ReconcileCluster struct {
 	client.Client
 	scheme   *runtime.Scheme
+	recorder record.EventRecorder
 	actuator Actuator
 }
 
 func (r *ReconcileCluster) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	logger := log.WithValues("cluster", request.Name, "namespace", request.Namespace)
+	ctx, span := tracing.StartReconcile(context.Background(), controllerName, request)
+	defer span.End()
+
 	cluster := &clusterv1alpha1.Cluster{}
-	err := r.Get(context.Background(), request.NamespacedName, cluster)
+	err := r.Get(ctx, request.NamespacedName, cluster)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			// Object not found, return.  Created objects are automatically garbage collected.
@@ -86,25 +129,28 @@ func (r *ReconcileCluster) Reconcile(request reconcile.Request) (reconcile.Resul
 		return reconcile.Result{}, err
 	}
 
-	name := cluster.Name
-	klog.Infof("Running reconcile Cluster for %q", name)
+	logger.Info("Reconciling Cluster")
 
 	// If object hasn't been deleted and doesn't have a finalizer, add one
 	// Add a finalizer to newly created objects.
 	if cluster.ObjectMeta.DeletionTimestamp.IsZero() {
-		finalizerCount := len(cluster.Finalizers)
+		updateFinalizers := false
 
-		if !util.Contains(cluster.Finalizers, metav1.FinalizerDeleteDependents) {
-			cluster.Finalizers = append(cluster.ObjectMeta.Finalizers, metav1.FinalizerDeleteDependents)
+		// The foreground deletion finalizer makes the garbage collector delete all the Machines
+		// of the Cluster at once, which would defeat the ordered deletion below.
+		if util.Contains(cluster.Finalizers, metav1.FinalizerDeleteDependents) {
+			cluster.Finalizers = util.Filter(cluster.Finalizers, metav1.FinalizerDeleteDependents)
+			updateFinalizers = true
 		}
 
 		if !util.Contains(cluster.Finalizers, clusterv1.ClusterFinalizer) {
 			cluster.Finalizers = append(cluster.ObjectMeta.Finalizers, clusterv1.ClusterFinalizer)
+			updateFinalizers = true
 		}
 
-		if len(cluster.Finalizers) > finalizerCount {
-			if err := r.Update(context.Background(), cluster); err != nil {
-				klog.Infof("Failed to add finalizer to cluster %q: %v", name, err)
+		if updateFinalizers {
+			if err := r.Update(ctx, cluster); err != nil {
+				logger.Error(err, "Failed to update finalizers")
 				return reconcile.Result{}, err
 			}
 
@@ -117,33 +163,91 @@ func (r *ReconcileCluster) Reconcile(request reconcile.Request) (reconcile.Resul
 	if !cluster.ObjectMeta.DeletionTimestamp.IsZero() {
 		// no-op if finalizer has been removed.
 		if !util.Contains(cluster.ObjectMeta.Finalizers, clusterv1.ClusterFinalizer) {
-			klog.Infof("reconciling cluster object %v causes a no-op as there is no finalizer.", name)
+			logger.Info("Reconciling Cluster causes a no-op as there is no finalizer")
 			return reconcile.Result{}, nil
 		}
 
-		klog.Infof("reconciling cluster object %v triggers delete.", name)
-		if err := r.actuator.Delete(cluster); err != nil {
-			klog.Errorf("Error deleting cluster object %v; %v", name, err)
+		// Delete the workers first, while the control plane can still drain them, then the
+		// control plane, and only then the infrastructure they run on.
+		deleted, err := r.deleteMachines(ctx, cluster)
+		if err != nil {
+			logger.Error(err, "Failed to delete Machines of Cluster")
 			return reconcile.Result{}, err
 		}
+		if !deleted {
+			logger.Info("Waiting for Machines of Cluster to be deleted")
+			return reconcile.Result{RequeueAfter: deletionRequeueAfter}, nil
+		}
+
+		if isExternallyManaged(cluster) {
+			logger.Info("Cluster infrastructure is externally managed, skipping delete")
+		} else {
+			logger.Info("Reconciling Cluster triggers delete")
+			if err := r.setInfrastructureDeleting(ctx, cluster, ""); err != nil {
+				logger.Error(err, "Failed to update status")
+				return reconcile.Result{}, err
+			}
+			_, actuatorSpan := tracing.Start(ctx, "Actuator.Delete")
+			err := r.actuator.Delete(cluster)
+			tracing.End(actuatorSpan, err)
+			if err != nil {
+				logger.Error(err, "Failed to delete Cluster")
+				r.recorder.Eventf(cluster, corev1.EventTypeWarning, "FailedDelete", "Failed to delete cluster: %v", err)
+				if err := r.setInfrastructureDeleting(ctx, cluster, err.Error()); err != nil {
+					logger.Error(err, "Failed to update status")
+				}
+				return reconcile.Result{}, err
+			}
+			r.recorder.Event(cluster, corev1.EventTypeNormal, "SuccessfulDelete", "Deleted cluster")
+		}
 		// Remove finalizer on successful deletion.
-		klog.Infof("cluster object %v deletion successful, removing finalizer.", name)
+		logger.Info("Cluster deletion successful, removing finalizer")
 		cluster.ObjectMeta.Finalizers = util.Filter(cluster.ObjectMeta.Finalizers, clusterv1.ClusterFinalizer)
-		if err := r.Client.Update(context.Background(), cluster); err != nil {
-			klog.Errorf("Error removing finalizer from cluster object %v; %v", name, err)
+		if err := r.Client.Update(ctx, cluster); err != nil {
+			logger.Error(err, "Failed to remove finalizer")
 			return reconcile.Result{}, err
 		}
 		return reconcile.Result{}, nil
 	}
 
-	klog.Infof("reconciling cluster object %v triggers idempotent reconcile.", name)
-	if err := r.actuator.Reconcile(cluster); err != nil {
+	if err := r.reconcileControlPlaneConditions(ctx, cluster); err != nil {
+		logger.Error(err, "Failed to update control plane conditions")
+		return reconcile.Result{}, err
+	}
+
+	if isExternallyManaged(cluster) {
+		logger.Info("Cluster infrastructure is externally managed, skipping reconcile")
+		return r.reconcileAPIEndpoints(ctx, cluster)
+	}
+
+	logger.Info("Reconciling Cluster triggers idempotent reconcile")
+	provisioned := len(cluster.Status.APIEndpoints) > 0
+	_, actuatorSpan := tracing.Start(ctx, "Actuator.Reconcile")
+	err = r.actuator.Reconcile(cluster)
+	tracing.End(actuatorSpan, err)
+	if err != nil {
 		if requeueErr, ok := errors.Cause(err).(controllerError.HasRequeueAfterError); ok {
-			klog.Infof("Actuator returned requeue-after error: %v", requeueErr)
+			logger.Info("Actuator returned requeue-after error", "requeueAfter", requeueErr.GetRequeueAfter())
 			return reconcile.Result{Requeue: true, RequeueAfter: requeueErr.GetRequeueAfter()}, nil
 		}
-		klog.Errorf("Error reconciling cluster object %v; %v", name, err)
+		logger.Error(err, "Failed to reconcile Cluster")
+		r.recorder.Eventf(cluster, corev1.EventTypeWarning, "FailedReconcile", "Failed to reconcile cluster: %v", err)
+		return reconcile.Result{}, err
+	}
+
+	if err := r.Get(ctx, request.NamespacedName, cluster); err != nil {
 		return reconcile.Result{}, err
 	}
-	return reconcile.Result{}, nil
+	if !provisioned && len(cluster.Status.APIEndpoints) > 0 {
+		r.recorder.Eventf(cluster, corev1.EventTypeNormal, "InfrastructureReady", "Control plane endpoint set to %s:%d",
+			cluster.Status.APIEndpoints[0].Host, cluster.Status.APIEndpoints[0].Port)
+	}
+	return r.reconcileAPIEndpoints(ctx, cluster)
+}
+
+// isExternallyManaged returns true if the infrastructure of the cluster is managed outside of
+// Cluster API, in which case the actuator must not provision or delete it.
+func isExternallyManaged(cluster *clusterv1.Cluster) bool {
+	_, ok := cluster.Annotations[clusterv1.ClusterManagedByAnnotation]
+	return ok
 }
@@ -0,0 +1,508 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileExternallyManaged(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+	now := metav1.Now()
+
+	testCases := []struct {
+		name            string
+		annotations     map[string]string
+		deleted         bool
+		reconcileCalled int64
+		deleteCalled    int64
+		events          []string
+	}{
+		{
+			name:            "reconcile managed cluster",
+			reconcileCalled: 1,
+		},
+		{
+			name:         "delete managed cluster",
+			deleted:      true,
+			deleteCalled: 1,
+			events:       []string{"Normal SuccessfulDelete Deleted cluster"},
+		},
+		{
+			name:        "reconcile externally managed cluster",
+			annotations: map[string]string{v1alpha1.ClusterManagedByAnnotation: "external"},
+		},
+		{
+			name:        "delete externally managed cluster",
+			annotations: map[string]string{v1alpha1.ClusterManagedByAnnotation: "external"},
+			deleted:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := &v1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "foo",
+					Namespace:   "default",
+					Annotations: tc.annotations,
+					Finalizers:  []string{v1alpha1.ClusterFinalizer},
+				},
+			}
+			if tc.deleted {
+				cluster.DeletionTimestamp = &now
+			}
+
+			a := newTestActuator()
+			recorder := record.NewFakeRecorder(32)
+			r := &ReconcileCluster{
+				Client:   fake.NewFakeClientWithScheme(scheme.Scheme, cluster),
+				scheme:   scheme.Scheme,
+				recorder: recorder,
+				actuator: a,
+			}
+
+			request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "foo", Namespace: "default"}}
+			if _, err := r.Reconcile(request); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if a.ReconcileCallCount != tc.reconcileCalled {
+				t.Errorf("expected actuator Reconcile to be called %d times, got %d", tc.reconcileCalled, a.ReconcileCallCount)
+			}
+			if a.DeleteCallCount != tc.deleteCalled {
+				t.Errorf("expected actuator Delete to be called %d times, got %d", tc.deleteCalled, a.DeleteCallCount)
+			}
+			close(recorder.Events)
+			var events []string
+			for e := range recorder.Events {
+				events = append(events, e)
+			}
+			if !reflect.DeepEqual(events, tc.events) {
+				t.Errorf("expected events %v, got %v", tc.events, events)
+			}
+		})
+	}
+}
+
+func TestReconcileDeletionOrder(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+	now := metav1.Now()
+
+	labels := map[string]string{v1alpha1.MachineClusterLabelName: "foo"}
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "foo",
+			Namespace:         "default",
+			Finalizers:        []string{v1alpha1.ClusterFinalizer},
+			DeletionTimestamp: &now,
+		},
+	}
+	workers := &v1alpha1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "workers", Namespace: "default", Labels: labels},
+	}
+	worker := &v1alpha1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default", Labels: labels},
+	}
+	controlPlane := &v1alpha1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "control-plane", Namespace: "default", Labels: labels},
+		Spec:       v1alpha1.MachineSpec{Versions: v1alpha1.MachineVersionInfo{ControlPlane: "1.15.0"}},
+	}
+	other := &v1alpha1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default", Labels: map[string]string{v1alpha1.MachineClusterLabelName: "bar"}},
+	}
+
+	a := newTestActuator()
+	r := &ReconcileCluster{
+		Client:   fake.NewFakeClientWithScheme(scheme.Scheme, cluster, workers, worker, controlPlane, other),
+		scheme:   scheme.Scheme,
+		recorder: record.NewFakeRecorder(32),
+		actuator: a,
+	}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "foo", Namespace: "default"}}
+	ctx := context.Background()
+
+	exists := func(obj runtime.Object, name string) bool {
+		err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, obj)
+		if err != nil && !apierrors.IsNotFound(err) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return err == nil
+	}
+	conditionStatus := func(conditionType v1alpha1.ClusterConditionType) corev1.ConditionStatus {
+		c := &v1alpha1.Cluster{}
+		if err := r.Get(ctx, request.NamespacedName, c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if condition := getCondition(c, conditionType); condition != nil {
+			return condition.Status
+		}
+		return ""
+	}
+
+	// The workers are deleted first.
+	if result, err := r.Reconcile(request); err != nil || result.RequeueAfter != deletionRequeueAfter {
+		t.Fatalf("expected to requeue after %v, got %+v and error %v", deletionRequeueAfter, result, err)
+	}
+	if exists(&v1alpha1.MachineDeployment{}, "workers") || exists(&v1alpha1.Machine{}, "worker") {
+		t.Error("expected the workers to be deleted")
+	}
+	if !exists(&v1alpha1.Machine{}, "control-plane") {
+		t.Error("expected the control plane to be deleted after the workers")
+	}
+	if status := conditionStatus(v1alpha1.WorkersDeletedCondition); status != corev1.ConditionFalse {
+		t.Errorf("expected %s to be False, got %q", v1alpha1.WorkersDeletedCondition, status)
+	}
+
+	// Then the control plane.
+	if result, err := r.Reconcile(request); err != nil || result.RequeueAfter != deletionRequeueAfter {
+		t.Fatalf("expected to requeue after %v, got %+v and error %v", deletionRequeueAfter, result, err)
+	}
+	if exists(&v1alpha1.Machine{}, "control-plane") {
+		t.Error("expected the control plane to be deleted")
+	}
+	if status := conditionStatus(v1alpha1.WorkersDeletedCondition); status != corev1.ConditionTrue {
+		t.Errorf("expected %s to be True, got %q", v1alpha1.WorkersDeletedCondition, status)
+	}
+	if a.DeleteCallCount != 0 {
+		t.Error("expected the infrastructure to be deleted after the control plane")
+	}
+
+	// Then the infrastructure.
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.DeleteCallCount != 1 {
+		t.Errorf("expected actuator Delete to be called once, got %d", a.DeleteCallCount)
+	}
+	if !exists(&v1alpha1.Machine{}, "other") {
+		t.Error("expected the Machines of other clusters to be left alone")
+	}
+	c := &v1alpha1.Cluster{}
+	if err := r.Get(ctx, request.NamespacedName, c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.Finalizers) != 0 {
+		t.Errorf("expected the finalizer to be removed, got %v", c.Finalizers)
+	}
+	if status := conditionStatus(v1alpha1.ControlPlaneDeletedCondition); status != corev1.ConditionTrue {
+		t.Errorf("expected %s to be True, got %q", v1alpha1.ControlPlaneDeletedCondition, status)
+	}
+	if status := conditionStatus(v1alpha1.InfrastructureDeletedCondition); status != corev1.ConditionFalse {
+		t.Errorf("expected %s to be False, got %q", v1alpha1.InfrastructureDeletedCondition, status)
+	}
+}
+
+func TestReconcileDeletionTimeout(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+	now := metav1.Now()
+	defer func(timeout time.Duration) { DeletionPhaseTimeout = timeout }(DeletionPhaseTimeout)
+	DeletionPhaseTimeout = 10 * time.Minute
+
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "foo",
+			Namespace:         "default",
+			Finalizers:        []string{v1alpha1.ClusterFinalizer},
+			DeletionTimestamp: &now,
+		},
+		Status: v1alpha1.ClusterStatus{
+			Conditions: []v1alpha1.ClusterCondition{{
+				Type:               v1alpha1.WorkersDeletedCondition,
+				Status:             corev1.ConditionFalse,
+				LastTransitionTime: metav1.NewTime(now.Add(-time.Hour)),
+				Reason:             "Deleting",
+			}},
+		},
+	}
+	// The worker is stuck deleting.
+	worker := &v1alpha1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "worker",
+			Namespace:         "default",
+			Labels:            map[string]string{v1alpha1.MachineClusterLabelName: "foo"},
+			DeletionTimestamp: &now,
+		},
+	}
+
+	a := newTestActuator()
+	r := &ReconcileCluster{
+		Client:   fake.NewFakeClientWithScheme(scheme.Scheme, cluster, worker),
+		scheme:   scheme.Scheme,
+		recorder: record.NewFakeRecorder(32),
+		actuator: a,
+	}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "foo", Namespace: "default"}}
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.DeleteCallCount != 1 {
+		t.Errorf("expected actuator Delete to be called once the timeout expired, got %d", a.DeleteCallCount)
+	}
+	c := &v1alpha1.Cluster{}
+	if err := r.Get(context.Background(), request.NamespacedName, c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	condition := getCondition(c, v1alpha1.WorkersDeletedCondition)
+	if condition == nil || condition.Status != corev1.ConditionTrue || condition.Reason != "DeletionTimedOut" {
+		t.Errorf("expected %s to be True with reason DeletionTimedOut, got %+v", v1alpha1.WorkersDeletedCondition, condition)
+	}
+}
+
+func TestReconcileControlPlaneConditions(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+
+	machine := func(name string, controlPlane, hasNode bool) *v1alpha1.Machine {
+		m := &v1alpha1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{v1alpha1.MachineClusterLabelName: "foo"},
+			},
+		}
+		if controlPlane {
+			m.Spec.Versions.ControlPlane = "1.15.3"
+		}
+		if hasNode {
+			m.Status.NodeRef = &corev1.ObjectReference{Kind: "Node", Name: name}
+		}
+		return m
+	}
+
+	endpoints := []v1alpha1.APIEndpoint{{Host: "10.0.0.1", Port: 6443}}
+	valid := v1alpha1.ClusterCondition{Type: v1alpha1.APIEndpointsValidCondition, Status: corev1.ConditionTrue}
+	invalid := v1alpha1.ClusterCondition{Type: v1alpha1.APIEndpointsValidCondition, Status: corev1.ConditionFalse}
+
+	testCases := []struct {
+		name        string
+		endpoints   []v1alpha1.APIEndpoint
+		conditions  []v1alpha1.ClusterCondition
+		machines    []runtime.Object
+		initialized corev1.ConditionStatus
+		ready       corev1.ConditionStatus
+	}{
+		{
+			name:     "no control plane machines",
+			machines: []runtime.Object{machine("worker", false, true)},
+		},
+		{
+			name:        "control plane machine without node",
+			endpoints:   endpoints,
+			conditions:  []v1alpha1.ClusterCondition{valid},
+			machines:    []runtime.Object{machine("cp-0", true, false), machine("worker", false, false)},
+			initialized: corev1.ConditionFalse,
+			ready:       corev1.ConditionFalse,
+		},
+		{
+			name:        "one of two control plane machines with node",
+			endpoints:   endpoints,
+			conditions:  []v1alpha1.ClusterCondition{valid},
+			machines:    []runtime.Object{machine("cp-0", true, true), machine("cp-1", true, false)},
+			initialized: corev1.ConditionTrue,
+			ready:       corev1.ConditionFalse,
+		},
+		{
+			name:        "all control plane machines with node",
+			endpoints:   endpoints,
+			conditions:  []v1alpha1.ClusterCondition{valid},
+			machines:    []runtime.Object{machine("cp-0", true, true), machine("cp-1", true, true)},
+			initialized: corev1.ConditionTrue,
+			ready:       corev1.ConditionTrue,
+		},
+		{
+			name:        "control plane machine with node and invalid endpoints",
+			endpoints:   endpoints,
+			conditions:  []v1alpha1.ClusterCondition{invalid},
+			machines:    []runtime.Object{machine("cp-0", true, true)},
+			initialized: corev1.ConditionFalse,
+			ready:       corev1.ConditionTrue,
+		},
+		{
+			name:        "control plane machine with node and no endpoints",
+			machines:    []runtime.Object{machine("cp-0", true, true)},
+			initialized: corev1.ConditionFalse,
+			ready:       corev1.ConditionTrue,
+		},
+		{
+			name:        "initialized control plane losing its nodes",
+			conditions:  []v1alpha1.ClusterCondition{{Type: v1alpha1.ControlPlaneInitializedCondition, Status: corev1.ConditionTrue}},
+			machines:    []runtime.Object{machine("cp-0", true, false)},
+			initialized: corev1.ConditionTrue,
+			ready:       corev1.ConditionFalse,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := &v1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+				Status:     v1alpha1.ClusterStatus{APIEndpoints: tc.endpoints, Conditions: tc.conditions},
+			}
+			r := &ReconcileCluster{
+				Client:   fake.NewFakeClientWithScheme(scheme.Scheme, append(tc.machines, cluster)...),
+				scheme:   scheme.Scheme,
+				recorder: record.NewFakeRecorder(32),
+			}
+
+			if err := r.reconcileControlPlaneConditions(context.Background(), cluster); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			c := &v1alpha1.Cluster{}
+			if err := r.Get(context.Background(), types.NamespacedName{Name: "foo", Namespace: "default"}, c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for conditionType, expected := range map[v1alpha1.ClusterConditionType]corev1.ConditionStatus{
+				v1alpha1.ControlPlaneInitializedCondition: tc.initialized,
+				v1alpha1.ControlPlaneReadyCondition:       tc.ready,
+			} {
+				var status corev1.ConditionStatus
+				if condition := getCondition(c, conditionType); condition != nil {
+					status = condition.Status
+				}
+				if status != expected {
+					t.Errorf("expected %s to be %q, got %q", conditionType, expected, status)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateAPIEndpoints(t *testing.T) {
+	lookupHost = func(host string) ([]string, error) {
+		if host == "api.example.com" {
+			return []string{"203.0.113.1"}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	defer func() { lookupHost = net.LookupHost }()
+
+	testCases := []struct {
+		name      string
+		endpoints []v1alpha1.APIEndpoint
+		expectErr bool
+	}{
+		{
+			name: "no endpoints",
+		},
+		{
+			name:      "ip endpoint",
+			endpoints: []v1alpha1.APIEndpoint{{Host: "10.0.0.1", Port: 6443}},
+		},
+		{
+			name:      "resolvable endpoint",
+			endpoints: []v1alpha1.APIEndpoint{{Host: "api.example.com", Port: 443}},
+		},
+		{
+			name:      "unresolvable endpoint",
+			endpoints: []v1alpha1.APIEndpoint{{Host: "api.invalid", Port: 443}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid port",
+			endpoints: []v1alpha1.APIEndpoint{{Host: "10.0.0.1", Port: 0}},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			problem := validateAPIEndpoints(tc.endpoints)
+			if (problem != "") != tc.expectErr {
+				t.Errorf("expected error: %v, got %q", tc.expectErr, problem)
+			}
+		})
+	}
+}
+
+func TestSetAPIEndpointsCondition(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+
+	testCases := []struct {
+		name      string
+		endpoints []v1alpha1.APIEndpoint
+		problem   string
+		expected  corev1.ConditionStatus
+	}{
+		{
+			name: "no endpoints",
+		},
+		{
+			name:      "valid endpoints",
+			endpoints: []v1alpha1.APIEndpoint{{Host: "10.0.0.1", Port: 6443}},
+			expected:  corev1.ConditionTrue,
+		},
+		{
+			name:      "invalid endpoints",
+			endpoints: []v1alpha1.APIEndpoint{{Host: "api.invalid", Port: 443}},
+			problem:   `host "api.invalid" cannot be resolved`,
+			expected:  corev1.ConditionFalse,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := &v1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+				Status:     v1alpha1.ClusterStatus{APIEndpoints: tc.endpoints},
+			}
+			r := &ReconcileCluster{
+				Client:   fake.NewFakeClientWithScheme(scheme.Scheme, cluster),
+				scheme:   scheme.Scheme,
+				recorder: record.NewFakeRecorder(32),
+			}
+
+			if err := r.setAPIEndpointsCondition(context.Background(), cluster, tc.problem); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			c := &v1alpha1.Cluster{}
+			if err := r.Get(context.Background(), types.NamespacedName{Name: "foo", Namespace: "default"}, c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var status corev1.ConditionStatus
+			if condition := getCondition(c, v1alpha1.APIEndpointsValidCondition); condition != nil {
+				status = condition.Status
+				if condition.Message != tc.problem {
+					t.Errorf("expected message %q, got %q", tc.problem, condition.Message)
+				}
+			}
+			if status != tc.expected {
+				t.Errorf("expected %s to be %q, got %q", v1alpha1.APIEndpointsValidCondition, tc.expected, status)
+			}
+			if c.Status.ErrorReason != "" || c.Status.ErrorMessage != "" {
+				t.Errorf("expected no error to be reported, got %q: %q", c.Status.ErrorReason, c.Status.ErrorMessage)
+			}
+		})
+	}
+}
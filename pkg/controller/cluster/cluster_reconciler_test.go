@@ -55,8 +55,9 @@ func TestReconcile(t *testing.T) {
 	c = mgr.GetClient()
 
 	a := newTestActuator()
-	recFn, requests := SetupTestReconcile(newReconciler(mgr, a))
-	if err := add(mgr, recFn); err != nil {
+	r := newReconciler(mgr, a)
+	recFn, requests := SetupTestReconcile(r)
+	if err := add(mgr, recFn, r.MachineToCluster); err != nil {
 		t.Fatalf("error adding controller to manager: %v", err)
 	}
 	defer close(StartTestManager(mgr, t))
@@ -0,0 +1,108 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// reconcileControlPlaneConditions sets the ControlPlaneInitialized and ControlPlaneReady conditions
+// of the cluster from the Nodes of its control plane Machines. The control plane is only initialized
+// once its API endpoints are valid, as the Nodes of worker Machines could not join it otherwise.
+// Clusters without control plane Machines, e.g. whose control plane is not managed by Cluster API,
+// are left alone.
+func (r *ReconcileCluster) reconcileControlPlaneConditions(ctx context.Context, cluster *clusterv1.Cluster) error {
+	machines := &clusterv1.MachineList{}
+	opts := []client.ListOptionFunc{
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels(map[string]string{clusterv1.MachineClusterLabelName: cluster.Name}),
+	}
+	if err := r.List(ctx, machines, opts...); err != nil {
+		return errors.Wrapf(err, "failed to list Machines of Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+
+	total, withNode := 0, 0
+	for i := range machines.Items {
+		m := &machines.Items[i]
+		if !util.IsControlPlaneMachine(m) || !m.DeletionTimestamp.IsZero() {
+			continue
+		}
+		total++
+		if m.Status.NodeRef != nil {
+			withNode++
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+
+	original := cluster.Status.DeepCopy()
+
+	initialized := getCondition(cluster, clusterv1.ControlPlaneInitializedCondition)
+	switch {
+	case initialized != nil && initialized.Status == corev1.ConditionTrue:
+	case withNode == 0:
+		setCondition(cluster, clusterv1.ControlPlaneInitializedCondition, corev1.ConditionFalse, "WaitingForNode", "Waiting for a control plane Machine to have a Node")
+	case !hasValidAPIEndpoints(cluster):
+		setCondition(cluster, clusterv1.ControlPlaneInitializedCondition, corev1.ConditionFalse, "WaitingForAPIEndpoints", "Waiting for the API endpoints of the cluster to be valid")
+	default:
+		setCondition(cluster, clusterv1.ControlPlaneInitializedCondition, corev1.ConditionTrue, "Initialized", "")
+		r.recorder.Event(cluster, corev1.EventTypeNormal, "ControlPlaneInitialized", "Control plane initialized")
+	}
+
+	if withNode == total {
+		setCondition(cluster, clusterv1.ControlPlaneReadyCondition, corev1.ConditionTrue, "Ready", "")
+	} else {
+		message := fmt.Sprintf("%d of %d control plane Machines have a Node", withNode, total)
+		setCondition(cluster, clusterv1.ControlPlaneReadyCondition, corev1.ConditionFalse, "WaitingForNodes", message)
+	}
+
+	if reflect.DeepEqual(original, &cluster.Status) {
+		return nil
+	}
+	return r.Status().Update(ctx, cluster)
+}
+
+// hasValidAPIEndpoints returns true if the cluster reports API endpoints which were checked to be valid.
+func hasValidAPIEndpoints(cluster *clusterv1.Cluster) bool {
+	condition := getCondition(cluster, clusterv1.APIEndpointsValidCondition)
+	return len(cluster.Status.APIEndpoints) > 0 && condition != nil && condition.Status == corev1.ConditionTrue
+}
+
+// MachineToCluster is a handler.ToRequestsFunc to be used to enqueue requests for reconciliation
+// of the Cluster of a control plane Machine.
+func (r *ReconcileCluster) MachineToCluster(o handler.MapObject) []reconcile.Request {
+	m, ok := o.Object.(*clusterv1.Machine)
+	if !ok || !util.IsControlPlaneMachine(m) || m.Labels[clusterv1.MachineClusterLabelName] == "" {
+		return nil
+	}
+
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{Namespace: m.Namespace, Name: m.Labels[clusterv1.MachineClusterLabelName]},
+	}}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// deleteMachines deletes the workers of the cluster, then its control plane, and returns true
+// once both are gone, so that the infrastructure of the cluster can be deleted. The progress
+// of each phase is reported in the conditions of the cluster.
+func (r *ReconcileCluster) deleteMachines(ctx context.Context, cluster *clusterv1.Cluster) (bool, error) {
+	original := cluster.Status.DeepCopy()
+
+	done, err := r.deleteMachinesPhase(ctx, cluster, clusterv1.WorkersDeletedCondition, false)
+	if err == nil && done {
+		done, err = r.deleteMachinesPhase(ctx, cluster, clusterv1.ControlPlaneDeletedCondition, true)
+	}
+
+	if !reflect.DeepEqual(original, &cluster.Status) {
+		if updateErr := r.Status().Update(ctx, cluster); updateErr != nil && err == nil {
+			return false, errors.Wrap(updateErr, "failed to update status")
+		}
+	}
+	return done, err
+}
+
+// deleteMachinesPhase deletes the MachineDeployments, MachineSets and Machines of either the
+// workers or the control plane of the cluster, and returns true once they are gone.
+func (r *ReconcileCluster) deleteMachinesPhase(ctx context.Context, cluster *clusterv1.Cluster, conditionType clusterv1.ClusterConditionType, controlPlane bool) (bool, error) {
+	if condition := getCondition(cluster, conditionType); condition != nil && condition.Status == corev1.ConditionTrue {
+		return true, nil
+	}
+
+	objs, err := r.clusterMachineObjects(ctx, cluster, controlPlane)
+	if err != nil {
+		return false, err
+	}
+	if len(objs) == 0 {
+		setCondition(cluster, conditionType, corev1.ConditionTrue, "Deleted", "")
+		return true, nil
+	}
+
+	message := fmt.Sprintf("Waiting for %d objects to be deleted", len(objs))
+	condition := setCondition(cluster, conditionType, corev1.ConditionFalse, "Deleting", message)
+	if DeletionPhaseTimeout > 0 && time.Since(condition.LastTransitionTime.Time) > DeletionPhaseTimeout {
+		message = fmt.Sprintf("%d objects were not deleted within %v", len(objs), DeletionPhaseTimeout)
+		setCondition(cluster, conditionType, corev1.ConditionTrue, "DeletionTimedOut", message)
+		r.recorder.Eventf(cluster, corev1.EventTypeWarning, "DeletionTimedOut", "Timed out waiting for %s: %s", conditionType, message)
+		return true, nil
+	}
+
+	deleted := 0
+	for _, obj := range objs {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return false, err
+		}
+		if !accessor.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		if err := r.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return false, errors.Wrapf(err, "failed to delete %T %q in namespace %q", obj, accessor.GetName(), accessor.GetNamespace())
+		}
+		deleted++
+	}
+
+	if deleted > 0 {
+		what := "workers"
+		if controlPlane {
+			what = "control plane"
+		}
+		log.Info("Deleting Machines of Cluster", "cluster", cluster.Name, "namespace", cluster.Namespace, "phase", conditionType, "deleted", deleted)
+		r.recorder.Eventf(cluster, corev1.EventTypeNormal, "Deleting", "Deleting %d objects of the %s", deleted, what)
+	}
+	return false, nil
+}
+
+// clusterMachineObjects returns the MachineDeployments, MachineSets and Machines of either the
+// workers or the control plane of the cluster.
+func (r *ReconcileCluster) clusterMachineObjects(ctx context.Context, cluster *clusterv1.Cluster, controlPlane bool) ([]runtime.Object, error) {
+	opts := []client.ListOptionFunc{
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels(map[string]string{clusterv1.MachineClusterLabelName: cluster.Name}),
+	}
+	var objs []runtime.Object
+
+	deployments := &clusterv1.MachineDeploymentList{}
+	if err := r.List(ctx, deployments, opts...); err != nil {
+		return nil, errors.Wrapf(err, "failed to list MachineDeployments of Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+	for i := range deployments.Items {
+		if util.IsControlPlaneMachineSpec(&deployments.Items[i].Spec.Template.Spec) == controlPlane {
+			objs = append(objs, &deployments.Items[i])
+		}
+	}
+
+	machineSets := &clusterv1.MachineSetList{}
+	if err := r.List(ctx, machineSets, opts...); err != nil {
+		return nil, errors.Wrapf(err, "failed to list MachineSets of Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+	for i := range machineSets.Items {
+		if util.IsControlPlaneMachineSpec(&machineSets.Items[i].Spec.Template.Spec) == controlPlane {
+			objs = append(objs, &machineSets.Items[i])
+		}
+	}
+
+	machines := &clusterv1.MachineList{}
+	if err := r.List(ctx, machines, opts...); err != nil {
+		return nil, errors.Wrapf(err, "failed to list Machines of Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+	for i := range machines.Items {
+		if util.IsControlPlaneMachine(&machines.Items[i]) == controlPlane {
+			objs = append(objs, &machines.Items[i])
+		}
+	}
+
+	return objs, nil
+}
+
+// getCondition returns the condition of the cluster with the given type, or nil if there is none.
+func getCondition(cluster *clusterv1.Cluster, conditionType clusterv1.ClusterConditionType) *clusterv1.ClusterCondition {
+	for i := range cluster.Status.Conditions {
+		if cluster.Status.Conditions[i].Type == conditionType {
+			return &cluster.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// setCondition sets the condition of the cluster with the given type, and returns it. The last
+// transition time is only updated when the status of the condition changes.
+func setCondition(cluster *clusterv1.Cluster, conditionType clusterv1.ClusterConditionType, status corev1.ConditionStatus, reason, message string) *clusterv1.ClusterCondition {
+	condition := getCondition(cluster, conditionType)
+	if condition == nil {
+		cluster.Status.Conditions = append(cluster.Status.Conditions, clusterv1.ClusterCondition{Type: conditionType})
+		condition = &cluster.Status.Conditions[len(cluster.Status.Conditions)-1]
+	}
+	if condition.Status != status {
+		condition.Status = status
+		condition.LastTransitionTime = metav1.Now()
+	}
+	condition.Reason = reason
+	condition.Message = message
+	return condition
+}
+
+// setInfrastructureDeleting records that the infrastructure of the cluster is being deleted,
+// along with the error of the last attempt if any.
+func (r *ReconcileCluster) setInfrastructureDeleting(ctx context.Context, cluster *clusterv1.Cluster, message string) error {
+	original := cluster.Status.DeepCopy()
+	setCondition(cluster, clusterv1.InfrastructureDeletedCondition, corev1.ConditionFalse, "Deleting", message)
+	if reflect.DeepEqual(original, &cluster.Status) {
+		return nil
+	}
+	return r.Status().Update(ctx, cluster)
+}
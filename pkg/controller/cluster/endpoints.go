@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// lookupHost resolves the host of an API endpoint, it is replaced in tests.
+var lookupHost = net.LookupHost
+
+// validateAPIEndpoints returns a description of the problem if one of the API endpoints reported
+// for the cluster has an invalid port or a host that can't be resolved.
+func validateAPIEndpoints(endpoints []clusterv1.APIEndpoint) string {
+	for _, endpoint := range endpoints {
+		if endpoint.Port <= 0 || endpoint.Port > 65535 {
+			return fmt.Sprintf("port %d of %q is not valid", endpoint.Port, endpoint.Host)
+		}
+		if net.ParseIP(endpoint.Host) != nil {
+			continue
+		}
+		if _, err := lookupHost(endpoint.Host); err != nil {
+			return fmt.Sprintf("host %q cannot be resolved: %v", endpoint.Host, err)
+		}
+	}
+	return ""
+}
+
+// reconcileAPIEndpoints checks the API endpoints reported for the cluster can be used to reach the
+// control plane, and checks them again later while they can't.
+func (r *ReconcileCluster) reconcileAPIEndpoints(ctx context.Context, cluster *clusterv1.Cluster) (reconcile.Result, error) {
+	problem := validateAPIEndpoints(cluster.Status.APIEndpoints)
+	if err := r.setAPIEndpointsCondition(ctx, cluster, problem); err != nil {
+		log.Error(err, "Failed to update status", "cluster", cluster.Name, "namespace", cluster.Namespace)
+		return reconcile.Result{}, err
+	}
+	if problem != "" {
+		return reconcile.Result{RequeueAfter: apiEndpointRequeueAfter}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+// setAPIEndpointsCondition reports the problem with the API endpoints of the cluster in its
+// APIEndpointsValidCondition, or marks them valid when problem is empty. The problem may be
+// transient, like a DNS record that didn't propagate yet, so the cluster error is left alone.
+func (r *ReconcileCluster) setAPIEndpointsCondition(ctx context.Context, cluster *clusterv1.Cluster, problem string) error {
+	if len(cluster.Status.APIEndpoints) == 0 && getCondition(cluster, clusterv1.APIEndpointsValidCondition) == nil {
+		return nil
+	}
+
+	original := cluster.Status.DeepCopy()
+	if problem == "" {
+		setCondition(cluster, clusterv1.APIEndpointsValidCondition, corev1.ConditionTrue, "Valid", "")
+	} else {
+		setCondition(cluster, clusterv1.APIEndpointsValidCondition, corev1.ConditionFalse, "InvalidAPIEndpoint", problem)
+	}
+	if reflect.DeepEqual(original, &cluster.Status) {
+		return nil
+	}
+
+	if problem != "" {
+		log.Info("Cluster has an invalid API endpoint", "cluster", cluster.Name, "namespace", cluster.Namespace, "problem", problem)
+		r.recorder.Event(cluster, corev1.EventTypeWarning, "InvalidAPIEndpoint", problem)
+	}
+	return r.Client.Status().Update(ctx, cluster)
+}
@@ -0,0 +1,369 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterresourceset
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/controller/predicates"
+	"sigs.k8s.io/cluster-api/pkg/controller/remote"
+	"sigs.k8s.io/cluster-api/pkg/tracing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// controllerName is the name of this controller
+const controllerName = "clusterresourceset_controller"
+
+// driftCheckInterval is the maximum amount of time between two checks of the objects of a
+// ClusterResourceSet with the Reconcile strategy.
+const driftCheckInterval = 5 * time.Minute
+
+var log = logf.Log.WithName("clusterresourceset-controller")
+
+// Add creates a new ClusterResourceSet Controller and adds it to the Manager with default RBAC.
+// The Manager will set fields on the Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	tracker, err := remote.ClusterCacheTrackerFor(mgr)
+	if err != nil {
+		return err
+	}
+	r := newReconciler(mgr, tracker)
+	return add(mgr, r, r.ClusterToClusterResourceSets)
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, tracker *remote.ClusterCacheTracker) *ReconcileClusterResourceSet {
+	return &ReconcileClusterResourceSet{
+		Client:       mgr.GetClient(),
+		scheme:       mgr.GetScheme(),
+		recorder:     mgr.GetEventRecorderFor(controllerName),
+		remoteClient: tracker.Client,
+	}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler, mapFn handler.ToRequestsFunc) error {
+	// Create a new controller
+	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to ClusterResourceSets.
+	if err := c.Watch(&source.Kind{Type: &v1alpha1.ClusterResourceSet{}}, &handler.EnqueueRequestForObject{}, predicates.WatchFilter()); err != nil {
+		return err
+	}
+
+	// Watch for changes to Clusters and reconcile the ClusterResourceSets selecting them.
+	return c.Watch(&source.Kind{Type: &v1alpha1.Cluster{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: mapFn}, predicates.WatchFilter())
+}
+
+var _ reconcile.Reconciler = &ReconcileClusterResourceSet{}
+
+// ReconcileClusterResourceSet reconciles a ClusterResourceSet object to apply its
+// resources to the workload clusters it selects.
+type ReconcileClusterResourceSet struct {
+	client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+
+	// remoteClient returns a client for the workload cluster.
+	remoteClient func(cluster *v1alpha1.Cluster) (client.Client, error)
+}
+
+// Reconcile creates the objects of the resources of a ClusterResourceSet in every
+// selected Cluster whose control plane is reachable, and records the resources applied
+// to a Cluster in its ClusterResourceSetBinding. Resources whose values didn't change
+// since they were applied are skipped. Objects that already exist in a workload cluster
+// are left untouched, unless the values of their resource changed.
+func (r *ReconcileClusterResourceSet) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	ctx, span := tracing.StartReconcile(context.Background(), controllerName, request)
+	defer span.End()
+
+	// Fetch the ClusterResourceSet instance.
+	crs := &v1alpha1.ClusterResourceSet{}
+	if err := r.Get(ctx, request.NamespacedName, crs); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, r.removeBindings(ctx, request.NamespacedName)
+		}
+		return reconcile.Result{}, err
+	}
+
+	if !crs.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+
+	clusters, err := r.selectedClusters(ctx, crs)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if len(clusters) == 0 {
+		return reconcile.Result{}, nil
+	}
+
+	resources, err := r.resources(ctx, crs)
+	if err != nil {
+		r.recorder.Eventf(crs, corev1.EventTypeWarning, "FailedGetResources", "%v", err)
+		return reconcile.Result{}, err
+	}
+
+	var errs []error
+	for _, cluster := range clusters {
+		if err := r.applyToCluster(ctx, crs, cluster, resources); err != nil {
+			r.recorder.Eventf(crs, corev1.EventTypeWarning, "FailedApply", "Failed to apply resources to Cluster %q: %v", cluster.Name, err)
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return reconcile.Result{}, kerrors.NewAggregate(errs)
+	}
+
+	// Changes made in the workload clusters aren't watched, check them periodically.
+	if crs.Spec.Strategy == v1alpha1.ClusterResourceSetStrategyReconcile {
+		return reconcile.Result{RequeueAfter: driftCheckInterval}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+// selectedClusters returns the Clusters selected by the ClusterResourceSet.
+func (r *ReconcileClusterResourceSet) selectedClusters(ctx context.Context, crs *v1alpha1.ClusterResourceSet) ([]*v1alpha1.Cluster, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&crs.Spec.ClusterSelector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse cluster selector of ClusterResourceSet %q", crs.Name)
+	}
+	// An empty selector selects no Cluster, like the selectors of MachineSets.
+	if selector.Empty() {
+		return nil, nil
+	}
+
+	clusterList := &v1alpha1.ClusterList{}
+	if err := r.List(ctx, clusterList, client.UseListOptions(&client.ListOptions{Namespace: crs.Namespace, LabelSelector: selector})); err != nil {
+		return nil, errors.Wrapf(err, "failed to list Clusters of ClusterResourceSet %q in namespace %q", crs.Name, crs.Namespace)
+	}
+
+	clusters := make([]*v1alpha1.Cluster, 0, len(clusterList.Items))
+	for i := range clusterList.Items {
+		if clusterList.Items[i].DeletionTimestamp.IsZero() {
+			clusters = append(clusters, &clusterList.Items[i])
+		}
+	}
+	return clusters, nil
+}
+
+// applyToCluster applies the resources to the workload cluster, once its control plane is
+// reachable, and records them in the ClusterResourceSetBinding of the Cluster. Applying
+// stops at the first resource that fails, as later resources may depend on it.
+func (r *ReconcileClusterResourceSet) applyToCluster(ctx context.Context, crs *v1alpha1.ClusterResourceSet, cluster *v1alpha1.Cluster, resources []*resource) error {
+	if len(cluster.Status.APIEndpoints) == 0 {
+		log.V(2).Info("Cluster has no API endpoint yet, won't apply resources", "cluster", cluster.Name, "namespace", cluster.Namespace)
+		return nil
+	}
+	if _, err := remote.GetKubeConfigSecret(r.Client, cluster.Name, cluster.Namespace); err != nil {
+		if err == remote.ErrSecretNotFound {
+			log.V(2).Info("Cluster doesn't have a kubeconfig secret yet, won't apply resources", "cluster", cluster.Name, "namespace", cluster.Namespace)
+			return nil
+		}
+		return err
+	}
+
+	c, err := r.remoteClient(cluster)
+	if err != nil {
+		return err
+	}
+
+	binding, err := r.getOrCreateBinding(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	original := binding.DeepCopy()
+	rsb := resourceSetBinding(binding, crs.Name)
+
+	var applyErr error
+	applied := 0
+	for _, res := range resources {
+		last := resourceBinding(rsb, res.ref)
+		unchanged := last != nil && last.Applied && last.Hash == res.hash
+		if unchanged && crs.Spec.Strategy != v1alpha1.ClusterResourceSetStrategyReconcile {
+			continue
+		}
+		// Existing objects are only updated once the resource was applied, either because its
+		// values changed or to revert the changes made to them.
+		update := last != nil && last.Applied
+
+		changed, err := applyObjects(ctx, c, res, update)
+		if err == nil && unchanged && changed == 0 {
+			continue
+		}
+
+		now := metav1.Now()
+		rb := v1alpha1.ResourceBinding{ResourceRef: res.ref, Hash: res.hash, LastAppliedTime: &now, Applied: true}
+		if err != nil {
+			rb.Applied = false
+			rb.ErrorMessage = err.Error()
+		}
+		setResourceBinding(rsb, rb)
+		if err != nil {
+			applyErr = err
+			break
+		}
+		applied++
+	}
+
+	if !reflect.DeepEqual(original, binding) {
+		if err := r.Update(ctx, binding); err != nil {
+			return errors.Wrapf(err, "failed to update ClusterResourceSetBinding %q in namespace %q", binding.Name, binding.Namespace)
+		}
+	}
+	if applied > 0 {
+		log.Info("Applied resources", "clusterresourceset", crs.Name, "cluster", cluster.Name, "namespace", cluster.Namespace, "applied", applied)
+		r.recorder.Eventf(crs, corev1.EventTypeNormal, "SuccessfulApply", "Applied %d resources to Cluster %q", applied, cluster.Name)
+	}
+	return applyErr
+}
+
+// applyObjects creates the objects of the resource in the workload cluster, and returns the
+// number of objects created or updated. Existing objects are updated if update is true and
+// they differ from the manifests, unless either is annotated to skip reconciliation.
+func applyObjects(ctx context.Context, c client.Client, res *resource, update bool) (int, error) {
+	changed := 0
+	for _, obj := range res.objs {
+		err := c.Create(ctx, obj.DeepCopy())
+		if err == nil {
+			changed++
+			continue
+		}
+		if !apierrors.IsAlreadyExists(err) {
+			return changed, errors.Wrapf(err, "failed to create %s %q of %s %q", obj.GetKind(), obj.GetName(), res.ref.Kind, res.ref.Name)
+		}
+		if !update || skipReconcile(obj) {
+			continue
+		}
+
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(obj.GroupVersionKind())
+		if err := c.Get(ctx, client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()}, existing); err != nil {
+			return changed, errors.Wrapf(err, "failed to get %s %q of %s %q", obj.GetKind(), obj.GetName(), res.ref.Kind, res.ref.Name)
+		}
+		if skipReconcile(existing) || isSubset(obj.Object, existing.Object) {
+			continue
+		}
+
+		desired := obj.DeepCopy()
+		desired.SetResourceVersion(existing.GetResourceVersion())
+		if err := c.Update(ctx, desired); err != nil {
+			return changed, errors.Wrapf(err, "failed to update %s %q of %s %q", obj.GetKind(), obj.GetName(), res.ref.Kind, res.ref.Name)
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+// getOrCreateBinding returns the ClusterResourceSetBinding of the Cluster, creating it if needed.
+// The binding is owned by the Cluster, so it's garbage collected along with it.
+func (r *ReconcileClusterResourceSet) getOrCreateBinding(ctx context.Context, cluster *v1alpha1.Cluster) (*v1alpha1.ClusterResourceSetBinding, error) {
+	binding := &v1alpha1.ClusterResourceSetBinding{}
+	key := client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Name}
+	if err := r.Get(ctx, key, binding); err == nil {
+		return binding, nil
+	} else if !apierrors.IsNotFound(err) {
+		return nil, errors.Wrapf(err, "failed to get ClusterResourceSetBinding %q in namespace %q", key.Name, key.Namespace)
+	}
+
+	binding = &v1alpha1.ClusterResourceSetBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: v1alpha1.SchemeGroupVersion.String(),
+				Kind:       "Cluster",
+				Name:       cluster.Name,
+				UID:        cluster.UID,
+			}},
+		},
+	}
+	if err := r.Create(ctx, binding); err != nil {
+		return nil, errors.Wrapf(err, "failed to create ClusterResourceSetBinding %q in namespace %q", key.Name, key.Namespace)
+	}
+	return binding, nil
+}
+
+// removeBindings removes a deleted ClusterResourceSet from the ClusterResourceSetBindings of its namespace.
+func (r *ReconcileClusterResourceSet) removeBindings(ctx context.Context, name types.NamespacedName) error {
+	bindings := &v1alpha1.ClusterResourceSetBindingList{}
+	if err := r.List(ctx, bindings, client.InNamespace(name.Namespace)); err != nil {
+		return errors.Wrapf(err, "failed to list ClusterResourceSetBindings in namespace %q", name.Namespace)
+	}
+
+	var errs []error
+	for i := range bindings.Items {
+		binding := &bindings.Items[i]
+		if !removeResourceSetBinding(binding, name.Name) {
+			continue
+		}
+		if err := r.Update(ctx, binding); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to update ClusterResourceSetBinding %q in namespace %q", binding.Name, binding.Namespace))
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+// ClusterToClusterResourceSets is a handler.ToRequestsFunc to be used to enqueue requests for
+// reconciliation of the ClusterResourceSets selecting a Cluster.
+func (r *ReconcileClusterResourceSet) ClusterToClusterResourceSets(o handler.MapObject) []reconcile.Request {
+	cluster, ok := o.Object.(*v1alpha1.Cluster)
+	if !ok {
+		return nil
+	}
+
+	crsList := &v1alpha1.ClusterResourceSetList{}
+	if err := r.List(context.Background(), crsList, client.InNamespace(cluster.Namespace)); err != nil {
+		log.Error(err, "Failed to list ClusterResourceSets", "cluster", cluster.Name, "namespace", cluster.Namespace)
+		return nil
+	}
+
+	var result []reconcile.Request
+	for i := range crsList.Items {
+		crs := &crsList.Items[i]
+		selector, err := metav1.LabelSelectorAsSelector(&crs.Spec.ClusterSelector)
+		if err != nil || selector.Empty() || !selector.Matches(labels.Set(cluster.Labels)) {
+			continue
+		}
+		result = append(result, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: crs.Namespace, Name: crs.Name},
+		})
+	}
+	return result
+}
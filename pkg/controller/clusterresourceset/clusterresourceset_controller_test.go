@@ -0,0 +1,401 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterresourceset
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/controller/remote"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const cniManifest = `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: cni
+  namespace: kube-system
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cni-config
+  namespace: kube-system
+data:
+  mtu: "1440"
+`
+
+const csiManifest = `{"apiVersion": "v1", "kind": "ServiceAccount", "metadata": {"name": "csi", "namespace": "kube-system"}}`
+
+func TestReconcile(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+
+	testCases := []struct {
+		name          string
+		cluster       *v1alpha1.Cluster
+		secretType    corev1.SecretType
+		expectErr     bool
+		expectObjects bool
+		expectApplied []bool
+	}{
+		{
+			name:          "selected cluster",
+			cluster:       newCluster("test", map[string]string{"cni": "calico"}, true),
+			secretType:    v1alpha1.ClusterResourceSetSecretType,
+			expectObjects: true,
+			expectApplied: []bool{true, true},
+		},
+		{
+			name:       "cluster not selected",
+			cluster:    newCluster("test", map[string]string{"cni": "weave"}, true),
+			secretType: v1alpha1.ClusterResourceSetSecretType,
+		},
+		{
+			name:       "control plane not ready",
+			cluster:    newCluster("test", map[string]string{"cni": "calico"}, false),
+			secretType: v1alpha1.ClusterResourceSetSecretType,
+		},
+		{
+			name:       "secret of another type",
+			cluster:    newCluster("test", map[string]string{"cni": "calico"}, true),
+			secretType: corev1.SecretTypeOpaque,
+			expectErr:  true,
+		},
+		{
+			name:       "selected cluster without kubeconfig",
+			cluster:    newCluster("other", map[string]string{"cni": "calico"}, true),
+			secretType: v1alpha1.ClusterResourceSetSecretType,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			crs := &v1alpha1.ClusterResourceSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "addons", Namespace: "default"},
+				Spec: v1alpha1.ClusterResourceSetSpec{
+					ClusterSelector: metav1.LabelSelector{MatchLabels: map[string]string{"cni": "calico"}},
+					Resources: []v1alpha1.ResourceRef{
+						{Name: "cni", Kind: v1alpha1.ConfigMapClusterResourceSetResourceKind},
+						{Name: "csi", Kind: v1alpha1.SecretClusterResourceSetResourceKind},
+					},
+				},
+			}
+			objs := []runtime.Object{
+				crs,
+				tc.cluster,
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: remote.KubeConfigSecretName("test"), Namespace: "default"},
+					Data:       map[string][]byte{remote.KubeConfigSecretKey: []byte("kubeconfig")},
+				},
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "cni", Namespace: "default"},
+					Data:       map[string]string{"cni.yaml": cniManifest},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "csi", Namespace: "default"},
+					Type:       tc.secretType,
+					Data:       map[string][]byte{"csi.json": []byte(csiManifest)},
+				},
+			}
+
+			// The CNI config already exists in the workload cluster and must be left untouched.
+			workload := fake.NewFakeClientWithScheme(scheme.Scheme, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "cni-config", Namespace: metav1.NamespaceSystem},
+				Data:       map[string]string{"mtu": "1500"},
+			})
+			r := &ReconcileClusterResourceSet{
+				Client:   fake.NewFakeClientWithScheme(scheme.Scheme, objs...),
+				scheme:   scheme.Scheme,
+				recorder: record.NewFakeRecorder(32),
+				remoteClient: func(*v1alpha1.Cluster) (client.Client, error) {
+					return workload, nil
+				},
+			}
+
+			key := types.NamespacedName{Name: crs.Name, Namespace: crs.Namespace}
+			if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); (err != nil) != tc.expectErr {
+				t.Fatalf("Expected error: %v, got %v", tc.expectErr, err)
+			}
+
+			for _, name := range []string{"cni", "csi"} {
+				sa := &corev1.ServiceAccount{}
+				err := workload.Get(context.Background(), client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: name}, sa)
+				if (err == nil) != tc.expectObjects {
+					t.Fatalf("Expected ServiceAccount %q to be created: %v, got %v", name, tc.expectObjects, err)
+				}
+			}
+
+			configMap := &corev1.ConfigMap{}
+			if err := workload.Get(context.Background(), client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: "cni-config"}, configMap); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if configMap.Data["mtu"] != "1500" {
+				t.Fatalf("Expected existing ConfigMap to be left untouched, got %v", configMap.Data)
+			}
+
+			binding := &v1alpha1.ClusterResourceSetBinding{}
+			err := r.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: tc.cluster.Name}, binding)
+			if tc.expectApplied == nil {
+				if err == nil {
+					t.Fatalf("Expected no ClusterResourceSetBinding, got %v", binding.Spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(binding.Spec.Bindings) != 1 || len(binding.Spec.Bindings[0].Resources) != len(tc.expectApplied) {
+				t.Fatalf("Expected a binding of %d resources, got %v", len(tc.expectApplied), binding.Spec.Bindings)
+			}
+			for i, rb := range binding.Spec.Bindings[0].Resources {
+				if rb.Applied != tc.expectApplied[i] || rb.Hash == "" || rb.LastAppliedTime == nil {
+					t.Fatalf("Expected resource %q to be applied: %v, got %v", rb.Name, tc.expectApplied[i], rb)
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileBinding(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+
+	crs := &v1alpha1.ClusterResourceSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "addons", Namespace: "default"},
+		Spec: v1alpha1.ClusterResourceSetSpec{
+			ClusterSelector: metav1.LabelSelector{MatchLabels: map[string]string{"cni": "calico"}},
+			Resources:       []v1alpha1.ResourceRef{{Name: "cni", Kind: v1alpha1.ConfigMapClusterResourceSetResourceKind}},
+		},
+	}
+	cni := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cni", Namespace: "default"},
+		Data:       map[string]string{"cni.yaml": cniManifest},
+	}
+	workload := fake.NewFakeClientWithScheme(scheme.Scheme)
+	r := &ReconcileClusterResourceSet{
+		Client: fake.NewFakeClientWithScheme(scheme.Scheme,
+			crs,
+			cni,
+			newCluster("test", map[string]string{"cni": "calico"}, true),
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: remote.KubeConfigSecretName("test"), Namespace: "default"},
+				Data:       map[string][]byte{remote.KubeConfigSecretKey: []byte("kubeconfig")},
+			},
+		),
+		scheme:   scheme.Scheme,
+		recorder: record.NewFakeRecorder(32),
+		remoteClient: func(*v1alpha1.Cluster) (client.Client, error) {
+			return workload, nil
+		},
+	}
+	ctx := context.Background()
+	key := types.NamespacedName{Name: crs.Name, Namespace: crs.Namespace}
+	configMapKey := client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: "cni-config"}
+
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Unchanged resources are not applied again.
+	configMap := &corev1.ConfigMap{}
+	if err := workload.Get(ctx, configMapKey, configMap); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := workload.Delete(ctx, configMap); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := workload.Get(ctx, configMapKey, &corev1.ConfigMap{}); err == nil {
+		t.Fatal("Expected unchanged resource not to be applied again")
+	}
+
+	// Changed resources are applied again, updating existing objects.
+	if err := workload.Create(ctx, &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: "cni-config", Namespace: metav1.NamespaceSystem},
+		Data:       map[string]string{"mtu": "1500"},
+	}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	cni.Data["cni.yaml"] = strings.Replace(cniManifest, "1440", "1400", 1)
+	if err := r.Update(ctx, cni); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := workload.Get(ctx, configMapKey, configMap); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if configMap.Data["mtu"] != "1400" {
+		t.Fatalf("Expected changed resource to be applied again, got %v", configMap.Data)
+	}
+
+	// Deleted ClusterResourceSets are removed from the bindings.
+	if err := r.Delete(ctx, crs); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	binding := &v1alpha1.ClusterResourceSetBinding{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "test"}, binding); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(binding.Spec.Bindings) != 0 {
+		t.Fatalf("Expected no bindings, got %v", binding.Spec.Bindings)
+	}
+}
+
+func TestReconcileStrategy(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+
+	crs := &v1alpha1.ClusterResourceSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "addons", Namespace: "default"},
+		Spec: v1alpha1.ClusterResourceSetSpec{
+			ClusterSelector: metav1.LabelSelector{MatchLabels: map[string]string{"cni": "calico"}},
+			Resources:       []v1alpha1.ResourceRef{{Name: "cni", Kind: v1alpha1.ConfigMapClusterResourceSetResourceKind}},
+			Strategy:        v1alpha1.ClusterResourceSetStrategyReconcile,
+		},
+	}
+	workload := fake.NewFakeClientWithScheme(scheme.Scheme)
+	r := &ReconcileClusterResourceSet{
+		Client: fake.NewFakeClientWithScheme(scheme.Scheme,
+			crs,
+			newCluster("test", map[string]string{"cni": "calico"}, true),
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "cni", Namespace: "default"},
+				Data:       map[string]string{"cni.yaml": cniManifest},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: remote.KubeConfigSecretName("test"), Namespace: "default"},
+				Data:       map[string][]byte{remote.KubeConfigSecretKey: []byte("kubeconfig")},
+			},
+		),
+		scheme:   scheme.Scheme,
+		recorder: record.NewFakeRecorder(32),
+		remoteClient: func(*v1alpha1.Cluster) (client.Client, error) {
+			return workload, nil
+		},
+	}
+	ctx := context.Background()
+	key := types.NamespacedName{Name: crs.Name, Namespace: crs.Namespace}
+	configMapKey := client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: "cni-config"}
+	serviceAccountKey := client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: "cni"}
+
+	result, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.RequeueAfter != driftCheckInterval {
+		t.Fatalf("Expected to be requeued after %v, got %v", driftCheckInterval, result)
+	}
+
+	// Deleted objects are re-created and modified objects are reverted.
+	if err := workload.Delete(ctx, &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "cni", Namespace: metav1.NamespaceSystem}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := workload.Get(ctx, configMapKey, configMap); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	configMap.Data["mtu"] = "9000"
+	if err := workload.Update(ctx, configMap); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := workload.Get(ctx, serviceAccountKey, &corev1.ServiceAccount{}); err != nil {
+		t.Fatalf("Expected deleted object to be re-created, got %v", err)
+	}
+	if err := workload.Get(ctx, configMapKey, configMap); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if configMap.Data["mtu"] != "1440" {
+		t.Fatalf("Expected modified object to be reverted, got %v", configMap.Data)
+	}
+
+	// Objects annotated to skip reconciliation are left untouched.
+	configMap.Data["mtu"] = "9000"
+	configMap.Annotations = map[string]string{v1alpha1.ClusterResourceSetSkipReconcileAnnotation: ""}
+	if err := workload.Update(ctx, configMap); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := workload.Get(ctx, configMapKey, configMap); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if configMap.Data["mtu"] != "9000" {
+		t.Fatalf("Expected annotated object to be left untouched, got %v", configMap.Data)
+	}
+}
+
+func TestClusterToClusterResourceSets(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+
+	selecting := &v1alpha1.ClusterResourceSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "selecting", Namespace: "default"},
+		Spec: v1alpha1.ClusterResourceSetSpec{
+			ClusterSelector: metav1.LabelSelector{MatchLabels: map[string]string{"cni": "calico"}},
+		},
+	}
+	other := &v1alpha1.ClusterResourceSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+		Spec: v1alpha1.ClusterResourceSetSpec{
+			ClusterSelector: metav1.LabelSelector{MatchLabels: map[string]string{"cni": "weave"}},
+		},
+	}
+	empty := &v1alpha1.ClusterResourceSet{ObjectMeta: metav1.ObjectMeta{Name: "empty", Namespace: "default"}}
+	otherNamespace := selecting.DeepCopy()
+	otherNamespace.Namespace = "other"
+
+	r := &ReconcileClusterResourceSet{
+		Client: fake.NewFakeClientWithScheme(scheme.Scheme, selecting, other, empty, otherNamespace),
+	}
+
+	cluster := newCluster("test", map[string]string{"cni": "calico"}, true)
+	requests := r.ClusterToClusterResourceSets(handler.MapObject{Meta: cluster, Object: cluster})
+	if len(requests) != 1 || requests[0].Name != "selecting" || requests[0].Namespace != "default" {
+		t.Fatalf("Expected a request for ClusterResourceSet %q, got %v", "selecting", requests)
+	}
+}
+
+func newCluster(name string, labels map[string]string, ready bool) *v1alpha1.Cluster {
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+	}
+	if ready {
+		cluster.Status.APIEndpoints = []v1alpha1.APIEndpoint{{Host: "10.0.0.1", Port: 6443}}
+	}
+	return cluster
+}
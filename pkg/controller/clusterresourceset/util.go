@@ -0,0 +1,229 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterresourceset
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// resource is a resource of a ClusterResourceSet along with the objects of its manifests.
+type resource struct {
+	ref v1alpha1.ResourceRef
+
+	// hash is the hash of the values of the resource.
+	hash string
+
+	// objs are the objects of the manifests, in the order of the keys of the values.
+	objs []*unstructured.Unstructured
+}
+
+// resources returns the resources of the ClusterResourceSet, in order.
+func (r *ReconcileClusterResourceSet) resources(ctx context.Context, crs *v1alpha1.ClusterResourceSet) ([]*resource, error) {
+	resources := make([]*resource, 0, len(crs.Spec.Resources))
+	for _, ref := range crs.Spec.Resources {
+		data, err := r.resourceData(ctx, crs.Namespace, ref)
+		if err != nil {
+			return nil, err
+		}
+
+		keys := make([]string, 0, len(data))
+		for k := range data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		res := &resource{ref: ref, hash: computeHash(keys, data)}
+		for _, k := range keys {
+			objs, err := decodeObjects(data[k])
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to decode key %q of %s %q", k, ref.Kind, ref.Name)
+			}
+			res.objs = append(res.objs, objs...)
+		}
+		resources = append(resources, res)
+	}
+	return resources, nil
+}
+
+// resourceData returns the values of the ConfigMap or Secret referenced by ref.
+func (r *ReconcileClusterResourceSet) resourceData(ctx context.Context, namespace string, ref v1alpha1.ResourceRef) (map[string][]byte, error) {
+	key := client.ObjectKey{Namespace: namespace, Name: ref.Name}
+
+	switch ref.Kind {
+	case v1alpha1.ConfigMapClusterResourceSetResourceKind:
+		configMap := &corev1.ConfigMap{}
+		if err := r.Get(ctx, key, configMap); err != nil {
+			return nil, errors.Wrapf(err, "failed to get ConfigMap %q in namespace %q", ref.Name, namespace)
+		}
+		data := make(map[string][]byte, len(configMap.Data))
+		for k, v := range configMap.Data {
+			data[k] = []byte(v)
+		}
+		return data, nil
+
+	case v1alpha1.SecretClusterResourceSetResourceKind:
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, key, secret); err != nil {
+			return nil, errors.Wrapf(err, "failed to get Secret %q in namespace %q", ref.Name, namespace)
+		}
+		if secret.Type != v1alpha1.ClusterResourceSetSecretType {
+			return nil, errors.Errorf("Secret %q in namespace %q is not of type %q", ref.Name, namespace, v1alpha1.ClusterResourceSetSecretType)
+		}
+		return secret.Data, nil
+	}
+
+	return nil, errors.Errorf("unsupported resource kind %q", ref.Kind)
+}
+
+// computeHash returns the hash of the values of a resource, covering their sorted keys.
+func computeHash(keys []string, data map[string][]byte) string {
+	hash := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(hash, "%s\x00%d\x00", k, len(data[k]))
+		hash.Write(data[k])
+	}
+	return fmt.Sprintf("sha256:%x", hash.Sum(nil))
+}
+
+// resourceSetBinding returns the binding of the ClusterResourceSet, adding it to the
+// ClusterResourceSetBinding if needed.
+func resourceSetBinding(binding *v1alpha1.ClusterResourceSetBinding, name string) *v1alpha1.ResourceSetBinding {
+	for i := range binding.Spec.Bindings {
+		if binding.Spec.Bindings[i].ClusterResourceSetName == name {
+			return &binding.Spec.Bindings[i]
+		}
+	}
+	binding.Spec.Bindings = append(binding.Spec.Bindings, v1alpha1.ResourceSetBinding{ClusterResourceSetName: name})
+	return &binding.Spec.Bindings[len(binding.Spec.Bindings)-1]
+}
+
+// removeResourceSetBinding removes the binding of the ClusterResourceSet, returning false
+// if there was none.
+func removeResourceSetBinding(binding *v1alpha1.ClusterResourceSetBinding, name string) bool {
+	for i := range binding.Spec.Bindings {
+		if binding.Spec.Bindings[i].ClusterResourceSetName == name {
+			binding.Spec.Bindings = append(binding.Spec.Bindings[:i], binding.Spec.Bindings[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// resourceBinding returns the last application of the resource, or nil if it was never applied.
+func resourceBinding(rsb *v1alpha1.ResourceSetBinding, ref v1alpha1.ResourceRef) *v1alpha1.ResourceBinding {
+	for i := range rsb.Resources {
+		if rsb.Resources[i].ResourceRef == ref {
+			return &rsb.Resources[i]
+		}
+	}
+	return nil
+}
+
+// setResourceBinding records the application of a resource.
+func setResourceBinding(rsb *v1alpha1.ResourceSetBinding, rb v1alpha1.ResourceBinding) {
+	if existing := resourceBinding(rsb, rb.ResourceRef); existing != nil {
+		*existing = rb
+		return
+	}
+	rsb.Resources = append(rsb.Resources, rb)
+}
+
+// skipReconcile returns true if the object is annotated to be left untouched once created.
+func skipReconcile(obj *unstructured.Unstructured) bool {
+	_, ok := obj.GetAnnotations()[v1alpha1.ClusterResourceSetSkipReconcileAnnotation]
+	return ok
+}
+
+// isSubset returns true if all the fields set in desired have the same value in live, so
+// that the fields defaulted or added by the API server and other controllers are ignored.
+func isSubset(desired, live interface{}) bool {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range d {
+			if !isSubset(v, l[k]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok || len(l) != len(d) {
+			return false
+		}
+		for i := range d {
+			if !isSubset(d[i], l[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Numbers are decoded from the manifests as float64, and from the API server as int64.
+	if df, ok := toFloat64(desired); ok {
+		lf, ok := toFloat64(live)
+		return ok && df == lf
+	}
+	return reflect.DeepEqual(desired, live)
+}
+
+// toFloat64 converts a number decoded from JSON to float64.
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// decodeObjects decodes the objects of a YAML or JSON manifest, skipping empty documents.
+func decodeObjects(data []byte) ([]*unstructured.Unstructured, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+
+	var objs []*unstructured.Unstructured
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if err == io.EOF {
+				return objs, nil
+			}
+			return nil, errors.Wrap(err, "failed to decode object")
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		objs = append(objs, obj)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterresourceset
+
+import (
+	"testing"
+)
+
+func TestIsSubset(t *testing.T) {
+	live := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "cni", "resourceVersion": "42"},
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"ports":    []interface{}{map[string]interface{}{"port": int64(53), "protocol": "UDP"}},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		desired  map[string]interface{}
+		expected bool
+	}{
+		{
+			name: "defaulted fields are ignored",
+			desired: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "cni"},
+				"spec": map[string]interface{}{
+					"replicas": float64(2),
+					"ports":    []interface{}{map[string]interface{}{"port": float64(53)}},
+				},
+			},
+			expected: true,
+		},
+		{
+			name:     "modified field",
+			desired:  map[string]interface{}{"spec": map[string]interface{}{"replicas": float64(3)}},
+			expected: false,
+		},
+		{
+			name:     "removed field",
+			desired:  map[string]interface{}{"spec": map[string]interface{}{"paused": true}},
+			expected: false,
+		},
+		{
+			name:     "removed list item",
+			desired:  map[string]interface{}{"spec": map[string]interface{}{"ports": []interface{}{nil, nil}}},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := isSubset(tc.desired, live); actual != tc.expected {
+				t.Fatalf("Expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"context"
+	"crypto/x509"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	clientcmdlatest "k8s.io/client-go/tools/clientcmd/api/latest"
+	clientcmdv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+	"k8s.io/client-go/tools/record"
+	certutil "k8s.io/client-go/util/cert"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/cert"
+	"sigs.k8s.io/cluster-api/pkg/controller/predicates"
+	"sigs.k8s.io/cluster-api/pkg/controller/remote"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sigs.k8s.io/yaml"
+)
+
+// controllerName is the name of this controller
+const controllerName = "kubeconfig_controller"
+
+const (
+	// checkInterval is the maximum amount of time between two checks of the same kubeconfig.
+	checkInterval = 12 * time.Hour
+
+	// certificateValidity is the validity of regenerated client certificates, it matches kubeadm's.
+	certificateValidity = 365 * 24 * time.Hour
+)
+
+var log = logf.Log.WithName("kubeconfig-controller")
+
+// RotationThreshold is how long before expiry the client certificate of a kubeconfig is regenerated.
+var RotationThreshold = 30 * 24 * time.Hour
+
+// Add creates a new Kubeconfig Controller and adds it to the Manager with default RBAC.
+// The Manager will set fields on the Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileKubeconfig{
+		Client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: mgr.GetEventRecorderFor(controllerName),
+	}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to Clusters.
+	return c.Watch(&source.Kind{Type: &v1alpha1.Cluster{}}, &handler.EnqueueRequestForObject{}, predicates.WatchFilter())
+}
+
+var _ reconcile.Reconciler = &ReconcileKubeconfig{}
+
+// ReconcileKubeconfig reconciles a Cluster object to keep the client certificate
+// of its kubeconfig secret from expiring.
+type ReconcileKubeconfig struct {
+	client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+}
+
+// Reconcile regenerates the client certificate of a Cluster's kubeconfig secret
+// from the cluster certificate authority when it is about to expire.
+func (r *ReconcileKubeconfig) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	ctx := context.Background()
+
+	// Fetch the Cluster instance.
+	cluster := &v1alpha1.Cluster{}
+	if err := r.Get(ctx, request.NamespacedName, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if !cluster.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+
+	secret, err := remote.GetKubeConfigSecret(r.Client, cluster.Name, cluster.Namespace)
+	if err != nil {
+		if err == remote.ErrSecretNotFound {
+			log.V(2).Info("Cluster doesn't have a kubeconfig secret yet, won't reconcile", "cluster", cluster.Name, "namespace", cluster.Namespace)
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	data, err := remote.KubeConfigFromSecret(secret)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	config, err := clientcmd.Load(data)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "failed to parse kubeconfig for Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+
+	authInfo := currentAuthInfo(config)
+	if authInfo == nil || len(authInfo.ClientCertificateData) == 0 {
+		log.V(2).Info("Kubeconfig doesn't use a client certificate, won't reconcile", "cluster", cluster.Name, "namespace", cluster.Namespace)
+		return reconcile.Result{}, nil
+	}
+
+	certs, err := certutil.ParseCertsPEM(authInfo.ClientCertificateData)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "failed to parse kubeconfig client certificate for Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+
+	if remaining := time.Until(certs[0].NotAfter) - RotationThreshold; remaining > 0 {
+		if remaining > checkInterval {
+			remaining = checkInterval
+		}
+		return reconcile.Result{RequeueAfter: remaining}, nil
+	}
+
+	ca, err := r.getCertificateAuthority(ctx, cluster)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("Kubeconfig expires soon and no certificate authority secret was found to rotate it",
+				"cluster", cluster.Name, "namespace", cluster.Namespace, "expiry", certs[0].NotAfter, "secret", remote.CASecretName(cluster.Name))
+			r.recorder.Eventf(cluster, corev1.EventTypeWarning, "KubeconfigExpiringSoon", "Kubeconfig client certificate expires on %v", certs[0].NotAfter)
+			return reconcile.Result{RequeueAfter: checkInterval}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if err := r.rotate(ctx, secret, config, authInfo, certs[0], ca); err != nil {
+		r.recorder.Eventf(cluster, corev1.EventTypeWarning, "FailedRotateKubeconfig", "%v", err)
+		return reconcile.Result{}, err
+	}
+
+	log.Info("Rotated kubeconfig client certificate", "cluster", cluster.Name, "namespace", cluster.Namespace)
+	r.recorder.Event(cluster, corev1.EventTypeNormal, "SuccessfulRotateKubeconfig", secret.Name)
+	return reconcile.Result{RequeueAfter: checkInterval}, nil
+}
+
+func (r *ReconcileKubeconfig) getCertificateAuthority(ctx context.Context, cluster *v1alpha1.Cluster) (*cert.CertificateAuthority, error) {
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{
+		Namespace: cluster.Namespace,
+		Name:      remote.CASecretName(cluster.Name),
+	}
+
+	if err := r.Client.Get(ctx, key, secret); err != nil {
+		return nil, err
+	}
+
+	return &cert.CertificateAuthority{
+		Certificate: secret.Data[corev1.TLSCertKey],
+		PrivateKey:  secret.Data[corev1.TLSPrivateKeyKey],
+	}, nil
+}
+
+// rotate replaces the client certificate of authInfo with a new one for the same subject
+// and stores the updated kubeconfig in the secret.
+func (r *ReconcileKubeconfig) rotate(ctx context.Context, secret *corev1.Secret, config *clientcmdapi.Config, authInfo *clientcmdapi.AuthInfo, current *x509.Certificate, ca *cert.CertificateAuthority) error {
+	certPEM, keyPEM, err := ca.NewClientCertAndKey(current.Subject, certificateValidity)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate kubeconfig client certificate")
+	}
+
+	authInfo.ClientCertificateData = certPEM
+	authInfo.ClientKeyData = keyPEM
+
+	out, err := writeKubeconfig(config)
+	if err != nil {
+		return err
+	}
+
+	secret.Data[remote.KubeConfigSecretKey] = out
+	return r.Client.Update(ctx, secret)
+}
+
+// writeKubeconfig serializes config. It is converted to its versioned form and marshaled with
+// encoding/json rather than through clientcmd.Write, whose json-iterator based encoder panics
+// on maps with recent Go releases.
+func writeKubeconfig(config *clientcmdapi.Config) ([]byte, error) {
+	versioned := &clientcmdv1.Config{}
+	if err := clientcmdlatest.Scheme.Convert(config, versioned, nil); err != nil {
+		return nil, errors.Wrap(err, "failed to convert kubeconfig")
+	}
+	versioned.APIVersion = clientcmdv1.SchemeGroupVersion.Version
+	versioned.Kind = "Config"
+
+	out, err := yaml.Marshal(versioned)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to serialize kubeconfig")
+	}
+	return out, nil
+}
+
+// currentAuthInfo returns the user of the current context of config, if any.
+func currentAuthInfo(config *clientcmdapi.Config) *clientcmdapi.AuthInfo {
+	context, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return nil
+	}
+	return config.AuthInfos[context.AuthInfo]
+}
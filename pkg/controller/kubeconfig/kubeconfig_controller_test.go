@@ -0,0 +1,158 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"bytes"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/tools/record"
+	certutil "k8s.io/client-go/util/cert"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/cert"
+	"sigs.k8s.io/cluster-api/pkg/cert/testutil"
+	"sigs.k8s.io/cluster-api/pkg/controller/remote"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var subject = pkix.Name{CommonName: "kubernetes-admin", Organization: []string{"system:masters"}}
+
+func TestReconcile(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+
+	ca := testutil.NewCertificateAuthority(t)
+	cluster := &v1alpha1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: remote.CASecretName("test"), Namespace: "default"},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       ca.Certificate,
+			corev1.TLSPrivateKeyKey: ca.PrivateKey,
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		validity      time.Duration
+		withCA        bool
+		expectRotated bool
+		expectEvent   bool
+	}{
+		{
+			name:     "client certificate far from expiry",
+			validity: 200 * 24 * time.Hour,
+			withCA:   true,
+		},
+		{
+			name:          "client certificate about to expire",
+			validity:      24 * time.Hour,
+			withCA:        true,
+			expectRotated: true,
+			expectEvent:   true,
+		},
+		{
+			name:        "client certificate about to expire without certificate authority",
+			validity:    24 * time.Hour,
+			expectEvent: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			kubeconfigSecret := newKubeconfigSecret(t, ca, tc.validity)
+			objs := []runtime.Object{cluster.DeepCopy(), kubeconfigSecret.DeepCopy()}
+			if tc.withCA {
+				objs = append(objs, caSecret.DeepCopy())
+			}
+
+			recorder := record.NewFakeRecorder(32)
+			r := &ReconcileKubeconfig{
+				Client:   fake.NewFakeClientWithScheme(scheme.Scheme, objs...),
+				scheme:   scheme.Scheme,
+				recorder: recorder,
+			}
+
+			key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+			if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			updated, err := remote.GetKubeConfigSecret(r.Client, cluster.Name, cluster.Namespace)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			rotated := !bytes.Equal(updated.Data[remote.KubeConfigSecretKey], kubeconfigSecret.Data[remote.KubeConfigSecretKey])
+			if rotated != tc.expectRotated {
+				t.Fatalf("Expected kubeconfig to be rotated: %v, got %v", tc.expectRotated, rotated)
+			}
+			if (len(recorder.Events) > 0) != tc.expectEvent {
+				t.Fatalf("Expected an event to be recorded: %v, got %d events", tc.expectEvent, len(recorder.Events))
+			}
+			if !rotated {
+				return
+			}
+
+			config, err := clientcmd.Load(updated.Data[remote.KubeConfigSecretKey])
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			certs, err := certutil.ParseCertsPEM(currentAuthInfo(config).ClientCertificateData)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if certs[0].Subject.CommonName != subject.CommonName {
+				t.Fatalf("Expected common name %q, got %q", subject.CommonName, certs[0].Subject.CommonName)
+			}
+			if time.Until(certs[0].NotAfter) < RotationThreshold {
+				t.Fatalf("Expected rotated certificate to be valid for more than %v, expires on %v", RotationThreshold, certs[0].NotAfter)
+			}
+		})
+	}
+}
+
+func newKubeconfigSecret(t *testing.T, ca *cert.CertificateAuthority, validity time.Duration) *corev1.Secret {
+	certPEM, keyPEM, err := ca.NewClientCertAndKey(subject, validity)
+	if err != nil {
+		t.Fatalf("unable to generate client certificate: %v", err)
+	}
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters["test"] = &clientcmdapi.Cluster{Server: "https://test:6443", CertificateAuthorityData: ca.Certificate}
+	config.AuthInfos["kubernetes-admin"] = &clientcmdapi.AuthInfo{ClientCertificateData: certPEM, ClientKeyData: keyPEM}
+	config.Contexts["kubernetes-admin@test"] = &clientcmdapi.Context{Cluster: "test", AuthInfo: "kubernetes-admin"}
+	config.CurrentContext = "kubernetes-admin@test"
+
+	data, err := writeKubeconfig(config)
+	if err != nil {
+		t.Fatalf("unable to serialize kubeconfig: %v", err)
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: remote.KubeConfigSecretName("test"), Namespace: "default"},
+		Data:       map[string][]byte{remote.KubeConfigSecretKey: data},
+	}
+}
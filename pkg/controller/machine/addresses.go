@@ -0,0 +1,81 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"reflect"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// addressTypePriority orders the addresses of a Machine, so that consumers picking the first
+// address of the list get one reachable from within the cluster.
+var addressTypePriority = map[corev1.NodeAddressType]int{
+	corev1.NodeInternalIP:  0,
+	corev1.NodeExternalIP:  1,
+	corev1.NodeInternalDNS: 2,
+	corev1.NodeExternalDNS: 3,
+	corev1.NodeHostName:    4,
+}
+
+// aggregateAddresses returns the addresses reported by the actuator without duplicates or
+// empty values, ordered by addressTypePriority. The order among addresses of the same type
+// is kept.
+func aggregateAddresses(addresses []corev1.NodeAddress) []corev1.NodeAddress {
+	if len(addresses) == 0 {
+		return addresses
+	}
+
+	seen := map[corev1.NodeAddress]bool{}
+	result := make([]corev1.NodeAddress, 0, len(addresses))
+	for _, a := range addresses {
+		if a.Address == "" || seen[a] {
+			continue
+		}
+		seen[a] = true
+		result = append(result, a)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return priority(result[i].Type) < priority(result[j].Type)
+	})
+	return result
+}
+
+// addressesChanged returns true if the actuator reported a different set of addresses than
+// before it ran, regardless of their order, duplicates or empty values.
+func addressesChanged(previous, current []corev1.NodeAddress) bool {
+	return !reflect.DeepEqual(addressSet(previous), addressSet(current))
+}
+
+func addressSet(addresses []corev1.NodeAddress) map[corev1.NodeAddress]bool {
+	set := map[corev1.NodeAddress]bool{}
+	for _, a := range addresses {
+		if a.Address != "" {
+			set[a] = true
+		}
+	}
+	return set
+}
+
+func priority(t corev1.NodeAddressType) int {
+	if p, ok := addressTypePriority[t]; ok {
+		return p
+	}
+	return len(addressTypePriority)
+}
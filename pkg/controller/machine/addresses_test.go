@@ -0,0 +1,116 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestAggregateAddresses(t *testing.T) {
+	testCases := []struct {
+		name      string
+		addresses []corev1.NodeAddress
+		expected  []corev1.NodeAddress
+	}{
+		{
+			name: "no addresses",
+		},
+		{
+			name: "duplicates and empty addresses",
+			addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: corev1.NodeInternalIP, Address: ""},
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+			},
+			expected: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+			},
+		},
+		{
+			name: "ordered by type",
+			addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeHostName, Address: "node-1"},
+				{Type: corev1.NodeExternalIP, Address: "203.0.113.1"},
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.2"},
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+			},
+			expected: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.2"},
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: corev1.NodeExternalIP, Address: "203.0.113.1"},
+				{Type: corev1.NodeHostName, Address: "node-1"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := aggregateAddresses(tc.addresses)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestAddressesChanged(t *testing.T) {
+	internal := corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: "10.0.0.1"}
+	hostname := corev1.NodeAddress{Type: corev1.NodeHostName, Address: "node-1"}
+
+	testCases := []struct {
+		name     string
+		previous []corev1.NodeAddress
+		current  []corev1.NodeAddress
+		expected bool
+	}{
+		{
+			name: "no addresses",
+		},
+		{
+			name:     "new addresses",
+			current:  []corev1.NodeAddress{internal},
+			expected: true,
+		},
+		{
+			name:     "reordered addresses",
+			previous: []corev1.NodeAddress{internal, hostname},
+			current:  []corev1.NodeAddress{hostname, internal},
+		},
+		{
+			name:     "duplicate and empty addresses",
+			previous: []corev1.NodeAddress{internal},
+			current:  []corev1.NodeAddress{internal, {Type: corev1.NodeHostName}, internal},
+		},
+		{
+			name:     "changed address",
+			previous: []corev1.NodeAddress{internal, hostname},
+			current:  []corev1.NodeAddress{internal, {Type: corev1.NodeHostName, Address: "node-2"}},
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := addressesChanged(tc.previous, tc.current); got != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
@@ -19,46 +19,70 @@ package machine
 import (
 	"context"
 	"os"
+	"reflect"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/klog"
+	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
 	controllerError "sigs.k8s.io/cluster-api/pkg/controller/error"
+	"sigs.k8s.io/cluster-api/pkg/controller/predicates"
 	"sigs.k8s.io/cluster-api/pkg/controller/remote"
+	"sigs.k8s.io/cluster-api/pkg/tracing"
 	"sigs.k8s.io/cluster-api/pkg/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
+	// controllerName is the name of this controller
+	controllerName = "machine_controller"
+
 	NodeNameEnvVar = "NODE_NAME"
+
+	// machineClassRefRequeueAfter is how long to wait before checking an invalid MachineClass reference again.
+	machineClassRefRequeueAfter = 30 * time.Second
 )
 
-var DefaultActuator Actuator
+var (
+	DefaultActuator Actuator
+
+	// MaxConcurrentReconciles is the number of Machines reconciled in parallel.
+	MaxConcurrentReconciles = 1
+
+	log = logf.Log.WithName("machine-controller")
+)
 
 func AddWithActuator(mgr manager.Manager, actuator Actuator) error {
-	return add(mgr, newReconciler(mgr, actuator))
+	tracker, err := remote.ClusterCacheTrackerFor(mgr)
+	if err != nil {
+		return err
+	}
+	return add(mgr, newReconciler(mgr, actuator, tracker))
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager, actuator Actuator) reconcile.Reconciler {
+func newReconciler(mgr manager.Manager, actuator Actuator, tracker *remote.ClusterCacheTracker) reconcile.Reconciler {
 	r := &ReconcileMachine{
 		Client:   mgr.GetClient(),
 		scheme:   mgr.GetScheme(),
 		nodeName: os.Getenv(NodeNameEnvVar),
+		recorder: mgr.GetEventRecorderFor(controllerName),
 		actuator: actuator,
+		tracker:  tracker,
 	}
 
 	if r.nodeName == "" {
-		klog.Warningf("Environment variable %q is not set, this controller will not protect against deleting its own machine", NodeNameEnvVar)
+		log.Info("Environment variable is not set, this controller will not protect against deleting its own machine", "variable", NodeNameEnvVar)
 	}
 
 	return r
@@ -67,7 +91,10 @@ func newReconciler(mgr manager.Manager, actuator Actuator) reconcile.Reconciler
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	// Create a new controller
-	c, err := controller.New("machine_controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New(controllerName, mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: MaxConcurrentReconciles,
+	})
 	if err != nil {
 		return err
 	}
@@ -76,16 +103,21 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	return c.Watch(
 		&source.Kind{Type: &clusterv1.Machine{}},
 		&handler.EnqueueRequestForObject{},
+		predicates.WatchFilter(),
 	)
 }
 
 // ReconcileMachine reconciles a Machine object
 type ReconcileMachine struct {
 	client.Client
-	scheme *runtime.Scheme
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
 
 	actuator Actuator
 
+	// tracker provides the clients of the workload clusters.
+	tracker *remote.ClusterCacheTracker
+
 	// nodeName is the name of the node on which the machine controller is running, if not present, it is loaded from NODE_NAME.
 	nodeName string
 }
@@ -93,8 +125,10 @@ type ReconcileMachine struct {
 // Reconcile reads that state of the cluster for a Machine object and makes changes based on the state read
 // and what is in the Machine.Spec
 func (r *ReconcileMachine) Reconcile(request reconcile.Request) (reconcile.Result, error) {
-	// TODO(mvladev): Can context be passed from Kubebuilder?
-	ctx := context.TODO()
+	ctx, span := tracing.StartReconcile(context.Background(), controllerName, request)
+	defer span.End()
+
+	logger := log.WithValues("machine", request.Name, "namespace", request.Namespace)
 
 	// Fetch the Machine instance
 	m := &clusterv1.Machine{}
@@ -110,8 +144,7 @@ func (r *ReconcileMachine) Reconcile(request reconcile.Request) (reconcile.Resul
 	}
 
 	// Implement controller logic here
-	name := m.Name
-	klog.Infof("Reconciling Machine %q", name)
+	logger.Info("Reconciling Machine")
 
 	// Cluster might be nil as some providers might not require a cluster object
 	// for machine management.
@@ -147,7 +180,7 @@ func (r *ReconcileMachine) Reconcile(request reconcile.Request) (reconcile.Resul
 
 		if len(m.Finalizers) > finalizerCount {
 			if err := r.Client.Update(ctx, m); err != nil {
-				klog.Infof("Failed to add finalizers to machine %q: %v", name, err)
+				logger.Error(err, "Failed to add finalizers")
 				return reconcile.Result{}, err
 			}
 
@@ -159,84 +192,137 @@ func (r *ReconcileMachine) Reconcile(request reconcile.Request) (reconcile.Resul
 	if !m.ObjectMeta.DeletionTimestamp.IsZero() {
 		// no-op if finalizer has been removed.
 		if !util.Contains(m.ObjectMeta.Finalizers, clusterv1.MachineFinalizer) {
-			klog.Infof("Reconciling machine %q causes a no-op as there is no finalizer", name)
+			logger.Info("Reconciling Machine causes a no-op as there is no finalizer")
 			return reconcile.Result{}, nil
 		}
 
 		if !r.isDeleteAllowed(m) {
-			klog.Infof("Deleting machine hosting this controller is not allowed. Skipping reconciliation of machine %q", name)
+			logger.Info("Deleting Machine hosting this controller is not allowed, skipping reconciliation")
 			return reconcile.Result{}, nil
 		}
 
-		klog.Infof("Reconciling machine %q triggers delete", name)
-		if err := r.actuator.Delete(ctx, cluster, m); err != nil {
+		logger.Info("Reconciling Machine triggers delete")
+		actuatorCtx, actuatorSpan := tracing.Start(ctx, "Actuator.Delete")
+		err := r.actuator.Delete(actuatorCtx, cluster, m)
+		tracing.End(actuatorSpan, err)
+		if err != nil {
 			if requeueErr, ok := errors.Cause(err).(controllerError.HasRequeueAfterError); ok {
-				klog.Infof("Actuator returned requeue-after error: %v", requeueErr)
+				logger.Info("Actuator returned requeue-after error", "requeueAfter", requeueErr.GetRequeueAfter())
 				return reconcile.Result{Requeue: true, RequeueAfter: requeueErr.GetRequeueAfter()}, nil
 			}
 
-			klog.Errorf("Failed to delete machine %q: %v", name, err)
+			logger.Error(err, "Failed to delete Machine")
+			r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedDelete", "Failed to delete machine: %v", err)
 			return reconcile.Result{}, err
 		}
 
 		if m.Status.NodeRef != nil {
-			klog.Infof("Deleting node %q for machine %q", m.Status.NodeRef.Name, m.Name)
+			logger.Info("Deleting Node", "node", m.Status.NodeRef.Name)
 			if err := r.deleteNode(ctx, cluster, m.Status.NodeRef.Name); err != nil && !apierrors.IsNotFound(err) {
-				klog.Errorf("Error deleting node %q for machine %q: %v", m.Status.NodeRef.Name, name, err)
+				logger.Error(err, "Failed to delete Node", "node", m.Status.NodeRef.Name)
+				r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedDeleteNode", "Failed to delete node %q: %v", m.Status.NodeRef.Name, err)
 				return reconcile.Result{}, err
 			}
+			r.recorder.Eventf(m, corev1.EventTypeNormal, "SuccessfulDeleteNode", "Deleted node %q", m.Status.NodeRef.Name)
 		}
 
 		// Remove finalizer on successful deletion.
 		m.ObjectMeta.Finalizers = util.Filter(m.ObjectMeta.Finalizers, clusterv1.MachineFinalizer)
 		if err := r.Client.Update(context.Background(), m); err != nil {
-			klog.Errorf("Failed to remove finalizer from machine %q: %v", name, err)
+			logger.Error(err, "Failed to remove finalizer")
 			return reconcile.Result{}, err
 		}
 
-		klog.Infof("Machine %q deletion successful", name)
+		logger.Info("Machine deletion successful")
+		r.recorder.Event(m, corev1.EventTypeNormal, "SuccessfulDelete", "Deleted machine")
 		return reconcile.Result{}, nil
 	}
 
-	exist, err := r.actuator.Exists(ctx, cluster, m)
+	// Check the MachineClass the provider spec is sourced from, if any, can be used. A missing
+	// MachineClass is usually created shortly after, so it isn't reported as a machine error.
+	problem, err := r.validateMachineClassRef(ctx, m)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if problem != "" {
+		logger.Info("Machine has an invalid MachineClass reference", "problem", problem)
+		r.recorder.Eventf(m, corev1.EventTypeWarning, "InvalidMachineClassRef", "Invalid MachineClass reference: %s", problem)
+		return reconcile.Result{RequeueAfter: machineClassRefRequeueAfter}, nil
+	}
+
+	// The actuator owns the addresses, they are only normalized once it reported new ones.
+	reported := append([]corev1.NodeAddress(nil), m.Status.Addresses...)
+
+	actuatorCtx, actuatorSpan := tracing.Start(ctx, "Actuator.Exists")
+	exist, err := r.actuator.Exists(actuatorCtx, cluster, m)
+	tracing.End(actuatorSpan, err)
 	if err != nil {
-		klog.Errorf("Failed to check if machine %q exists: %v", name, err)
+		logger.Error(err, "Failed to check if Machine exists")
 		return reconcile.Result{}, err
 	}
 
 	if exist {
-		klog.Infof("Reconciling machine %q triggers idempotent update", name)
-		if err := r.actuator.Update(ctx, cluster, m); err != nil {
+		logger.Info("Reconciling Machine triggers idempotent update")
+		actuatorCtx, actuatorSpan := tracing.Start(ctx, "Actuator.Update")
+		err := r.actuator.Update(actuatorCtx, cluster, m)
+		tracing.End(actuatorSpan, err)
+		if err != nil {
 			if requeueErr, ok := errors.Cause(err).(controllerError.HasRequeueAfterError); ok {
-				klog.Infof("Actuator returned requeue-after error: %v", requeueErr)
+				logger.Info("Actuator returned requeue-after error", "requeueAfter", requeueErr.GetRequeueAfter())
 				return reconcile.Result{Requeue: true, RequeueAfter: requeueErr.GetRequeueAfter()}, nil
 			}
 
-			klog.Errorf(`Error updating machine "%s/%s": %v`, m.Namespace, name, err)
+			logger.Error(err, "Failed to update Machine")
+			r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedUpdate", "Failed to update machine: %v", err)
 			return reconcile.Result{}, err
 		}
 
-		return reconcile.Result{}, nil
+		return reconcile.Result{}, r.normalizeAddresses(ctx, m, reported)
 	}
 
 	// Machine resource created. Machine does not yet exist.
-	klog.Infof("Reconciling machine object %v triggers idempotent create.", m.ObjectMeta.Name)
-	if err := r.actuator.Create(ctx, cluster, m); err != nil {
+	logger.Info("Reconciling Machine triggers idempotent create")
+	actuatorCtx, actuatorSpan = tracing.Start(ctx, "Actuator.Create")
+	err = r.actuator.Create(actuatorCtx, cluster, m)
+	tracing.End(actuatorSpan, err)
+	if err != nil {
 		if requeueErr, ok := errors.Cause(err).(controllerError.HasRequeueAfterError); ok {
-			klog.Infof("Actuator returned requeue-after error: %v", requeueErr)
+			logger.Info("Actuator returned requeue-after error", "requeueAfter", requeueErr.GetRequeueAfter())
 			return reconcile.Result{Requeue: true, RequeueAfter: requeueErr.GetRequeueAfter()}, nil
 		}
 
-		klog.Warningf("Failed to create machine %q: %v", name, err)
+		logger.Error(err, "Failed to create Machine")
+		r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedCreate", "Failed to create machine: %v", err)
 		return reconcile.Result{}, err
 	}
 
-	return reconcile.Result{}, nil
+	r.recorder.Event(m, corev1.EventTypeNormal, "SuccessfulCreate", "Created machine")
+	return reconcile.Result{}, r.normalizeAddresses(ctx, m, reported)
+}
+
+// normalizeAddresses removes duplicate and empty addresses and orders them by type, if the
+// actuator reported a different set of addresses than before it ran.
+func (r *ReconcileMachine) normalizeAddresses(ctx context.Context, m *clusterv1.Machine, reported []corev1.NodeAddress) error {
+	if !addressesChanged(reported, m.Status.Addresses) {
+		return nil
+	}
+
+	addresses := aggregateAddresses(m.Status.Addresses)
+	if reflect.DeepEqual(addresses, m.Status.Addresses) {
+		return nil
+	}
+
+	m.Status.Addresses = addresses
+	if err := r.Client.Status().Update(ctx, m); err != nil {
+		log.Error(err, "Failed to update addresses", "machine", m.Name, "namespace", m.Namespace)
+		return err
+	}
+	return nil
 }
 
 func (r *ReconcileMachine) getCluster(ctx context.Context, machine *clusterv1.Machine) (*clusterv1.Cluster, error) {
 	if machine.Labels[clusterv1.MachineClusterLabelName] == "" {
-		klog.Infof("Machine %q in namespace %q doesn't specify %q label, assuming nil cluster", machine.Name, machine.Namespace, clusterv1.MachineClusterLabelName)
+		log.Info("Machine doesn't specify the cluster label, assuming nil cluster", "machine", machine.Name, "namespace", machine.Namespace, "label", clusterv1.MachineClusterLabelName)
 		return nil, nil
 	}
 
@@ -267,7 +353,7 @@ func (r *ReconcileMachine) isDeleteAllowed(machine *clusterv1.Machine) bool {
 
 	node := &corev1.Node{}
 	if err := r.Client.Get(context.Background(), client.ObjectKey{Name: r.nodeName}, node); err != nil {
-		klog.Infof("Failed to determine if controller's node %q is associated with machine %q: %v", r.nodeName, machine.Name, err)
+		log.Error(err, "Failed to determine if the Node of the controller is associated with the Machine", "node", r.nodeName, "machine", machine.Name, "namespace", machine.Namespace)
 		return true
 	}
 
@@ -288,17 +374,10 @@ func (r *ReconcileMachine) deleteNode(ctx context.Context, cluster *clusterv1.Cl
 	}
 
 	// Otherwise, proceed to get the remote cluster client and get the Node.
-	remoteClient, err := remote.NewClusterClient(r.Client, cluster)
-	if err != nil {
-		klog.Errorf("Error creating a remote client for cluster %q while deleting Machine %q, won't retry: %v",
-			cluster.Name, name, err)
-		return nil
-	}
-
-	corev1Remote, err := remoteClient.CoreV1()
+	corev1Remote, err := r.tracker.CoreV1(cluster)
 	if err != nil {
-		klog.Errorf("Error creating a remote client for cluster %q while deleting Machine %q, won't retry: %v",
-			cluster.Name, name, err)
+		log.Error(err, "Failed to create a remote client while deleting Node, won't retry",
+			"cluster", cluster.Name, "namespace", cluster.Namespace, "node", name)
 		return nil
 	}
 
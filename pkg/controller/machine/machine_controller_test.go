@@ -23,6 +23,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -157,6 +158,7 @@ func TestReconcileRequest(t *testing.T) {
 		r := &ReconcileMachine{
 			Client:   fake.NewFakeClient(&clusterList, &machine1, &machine2, &machine3),
 			scheme:   scheme.Scheme,
+			recorder: record.NewFakeRecorder(32),
 			actuator: act,
 		}
 
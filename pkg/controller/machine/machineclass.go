@@ -0,0 +1,56 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// validateMachineClassRef returns a description of the problem if the MachineClass referenced by the
+// provider spec of the machine doesn't match the v1alpha1 contract or doesn't exist.
+func (r *ReconcileMachine) validateMachineClassRef(ctx context.Context, m *clusterv1.Machine) (string, error) {
+	valueFrom := m.Spec.ProviderSpec.ValueFrom
+	if valueFrom == nil || valueFrom.MachineClass == nil || valueFrom.MachineClass.ObjectReference == nil {
+		return "", nil
+	}
+
+	ref := valueFrom.MachineClass.ObjectReference
+	if ref.APIVersion != "" && ref.APIVersion != clusterv1.SchemeGroupVersion.String() {
+		return fmt.Sprintf("apiVersion %q is not supported, expected %q", ref.APIVersion, clusterv1.SchemeGroupVersion), nil
+	}
+	if ref.Kind != "" && ref.Kind != "MachineClass" {
+		return fmt.Sprintf("kind %q is not supported, expected %q", ref.Kind, "MachineClass"), nil
+	}
+
+	key := client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}
+	if key.Namespace == "" {
+		key.Namespace = m.Namespace
+	}
+
+	if err := r.Client.Get(ctx, key, &clusterv1.MachineClass{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("MachineClass %q not found", key), nil
+		}
+		return "", err
+	}
+	return "", nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileMachineClassRef(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+
+	class := &v1alpha1.MachineClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "small", Namespace: "default"},
+	}
+
+	testCases := []struct {
+		name            string
+		ref             *corev1.ObjectReference
+		expectedMessage string
+		expectedCreated int64
+	}{
+		{
+			name:            "no MachineClass",
+			expectedCreated: 1,
+		},
+		{
+			name:            "existing MachineClass",
+			ref:             &corev1.ObjectReference{Kind: "MachineClass", APIVersion: "cluster.k8s.io/v1alpha1", Name: "small"},
+			expectedCreated: 1,
+		},
+		{
+			name:            "missing MachineClass",
+			ref:             &corev1.ObjectReference{Name: "large"},
+			expectedMessage: `MachineClass "default/large" not found`,
+		},
+		{
+			name:            "unsupported apiVersion",
+			ref:             &corev1.ObjectReference{Kind: "MachineClass", APIVersion: "cluster.x-k8s.io/v1alpha2", Name: "small"},
+			expectedMessage: `apiVersion "cluster.x-k8s.io/v1alpha2" is not supported`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			machine := &v1alpha1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "foo",
+					Namespace:  "default",
+					Finalizers: []string{v1alpha1.MachineFinalizer},
+				},
+			}
+			if tc.ref != nil {
+				machine.Spec.ProviderSpec.ValueFrom = &v1alpha1.ProviderSpecSource{
+					MachineClass: &v1alpha1.MachineClassRef{ObjectReference: tc.ref},
+				}
+			}
+
+			act := newTestActuator()
+			recorder := record.NewFakeRecorder(32)
+			r := &ReconcileMachine{
+				Client:   fake.NewFakeClient(class, machine),
+				scheme:   scheme.Scheme,
+				recorder: recorder,
+				actuator: act,
+			}
+
+			key := types.NamespacedName{Name: "foo", Namespace: "default"}
+			if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if act.CreateCallCount != tc.expectedCreated {
+				t.Errorf("expected %d createCallCount, got %d", tc.expectedCreated, act.CreateCallCount)
+			}
+
+			got := &v1alpha1.Machine{}
+			if err := r.Client.Get(context.TODO(), key, got); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Status.ErrorReason != nil || got.Status.ErrorMessage != nil {
+				t.Errorf("expected no error reason or message, got %v: %v", got.Status.ErrorReason, got.Status.ErrorMessage)
+			}
+
+			var warning string
+			for len(recorder.Events) > 0 {
+				if event := <-recorder.Events; strings.Contains(event, "InvalidMachineClassRef") {
+					warning = event
+				}
+			}
+			switch {
+			case tc.expectedMessage == "" && warning != "":
+				t.Errorf("expected no InvalidMachineClassRef event, got %q", warning)
+			case tc.expectedMessage != "" && !strings.Contains(warning, tc.expectedMessage):
+				t.Errorf("expected InvalidMachineClassRef event containing %q, got %q", tc.expectedMessage, warning)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinedeployment
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	dutil "sigs.k8s.io/cluster-api/pkg/controller/machinedeployment/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// machineClassKey returns the key of the MachineClass referenced by the machine template
+// of the deployment, if any.
+func machineClassKey(d *v1alpha1.MachineDeployment) *client.ObjectKey {
+	valueFrom := d.Spec.Template.Spec.ProviderSpec.ValueFrom
+	if valueFrom == nil || valueFrom.MachineClass == nil || valueFrom.MachineClass.ObjectReference == nil {
+		return nil
+	}
+
+	key := client.ObjectKey{
+		Namespace: valueFrom.MachineClass.Namespace,
+		Name:      valueFrom.MachineClass.Name,
+	}
+	if key.Namespace == "" {
+		key.Namespace = d.Namespace
+	}
+	return &key
+}
+
+// syncMachineClassHash records the hash of the MachineClass referenced by the deployment. When the
+// MachineClass changed since it was last recorded, the hash is also set on the machine template so
+// that a new MachineSet is rolled out. A missing MachineClass has no hash, the deployment is
+// reconciled again once it is created. It returns true if the deployment was updated.
+func (r *ReconcileMachineDeployment) syncMachineClassHash(d *v1alpha1.MachineDeployment) (bool, error) {
+	key := machineClassKey(d)
+	if key == nil {
+		return false, nil
+	}
+
+	class := &v1alpha1.MachineClass{}
+	if err := r.Client.Get(context.Background(), *key, class); apierrors.IsNotFound(err) {
+		log.Info("MachineClass not found", "machinedeployment", d.Name, "namespace", d.Namespace, "machineclass", key.Name)
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrapf(err, "failed to get MachineClass %q for MachineDeployment %q", key, d.Name)
+	}
+
+	hash := fmt.Sprintf("%d", dutil.ComputeMachineClassHash(class))
+	previous, recorded := d.Annotations[dutil.MachineClassHashAnnotation]
+	if previous == hash {
+		return false, nil
+	}
+
+	if d.Annotations == nil {
+		d.Annotations = map[string]string{}
+	}
+	d.Annotations[dutil.MachineClassHashAnnotation] = hash
+
+	// Only roll out on changes, the first time the MachineClass is seen the existing
+	// machines have been created from its current configuration.
+	if recorded {
+		if d.Spec.Template.Annotations == nil {
+			d.Spec.Template.Annotations = map[string]string{}
+		}
+		d.Spec.Template.Annotations[dutil.MachineClassHashAnnotation] = hash
+		log.Info("MachineClass changed, rolling out", "machinedeployment", d.Name, "namespace", d.Namespace, "machineclass", key.Name)
+	}
+
+	if err := r.Client.Update(context.Background(), d); err != nil {
+		return false, errors.Wrapf(err, "failed to record MachineClass hash of MachineDeployment %q", d.Name)
+	}
+	return true, nil
+}
+
+// MachineClassToDeployments is a handler.ToRequestsFunc to be used to enqueue requests for reconciliation
+// for MachineDeployments referencing a MachineClass.
+func (r *ReconcileMachineDeployment) MachineClassToDeployments(o handler.MapObject) []reconcile.Request {
+	result := []reconcile.Request{}
+
+	dList := &v1alpha1.MachineDeploymentList{}
+	if err := r.Client.List(context.Background(), dList); err != nil {
+		log.Error(err, "Failed to list MachineDeployments for MachineClass", "machineclass", o.Meta.GetName(), "namespace", o.Meta.GetNamespace())
+		return nil
+	}
+
+	for idx := range dList.Items {
+		d := &dList.Items[idx]
+		key := machineClassKey(d)
+		if key == nil || key.Namespace != o.Meta.GetNamespace() || key.Name != o.Meta.GetName() {
+			continue
+		}
+
+		name := client.ObjectKey{Namespace: d.Namespace, Name: d.Name}
+		result = append(result, reconcile.Request{NamespacedName: name})
+	}
+
+	return result
+}
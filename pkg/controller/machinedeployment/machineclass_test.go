@@ -0,0 +1,147 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinedeployment
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	dutil "sigs.k8s.io/cluster-api/pkg/controller/machinedeployment/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+func newMachineClass(providerSpec string) *v1alpha1.MachineClass {
+	return &v1alpha1.MachineClass{
+		ObjectMeta:   metav1.ObjectMeta{Name: "small", Namespace: "test"},
+		ProviderSpec: runtime.RawExtension{Raw: []byte(providerSpec)},
+	}
+}
+
+func newMachineDeploymentWithClass(name string) *v1alpha1.MachineDeployment {
+	d := &v1alpha1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"}}
+	d.Spec.Template.Spec.ProviderSpec.ValueFrom = &v1alpha1.ProviderSpecSource{
+		MachineClass: &v1alpha1.MachineClassRef{ObjectReference: &corev1.ObjectReference{Name: "small"}},
+	}
+	return d
+}
+
+func TestSyncMachineClassHash(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+
+	class := newMachineClass(`{"instanceType":"small"}`)
+	changed := newMachineDeploymentWithClass("changed")
+	changed.Annotations = map[string]string{dutil.MachineClassHashAnnotation: "0"}
+	missing := newMachineDeploymentWithClass("missing")
+	missing.Spec.Template.Spec.ProviderSpec.ValueFrom.MachineClass.Name = "large"
+
+	tests := []struct {
+		desc           string
+		deployment     *v1alpha1.MachineDeployment
+		expectUpdated  bool
+		expectRollout  bool
+		expectRecorded bool
+	}{
+		{
+			desc:       "no MachineClass",
+			deployment: &v1alpha1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Name: "inline", Namespace: "test"}},
+		},
+		{
+			desc:       "missing MachineClass",
+			deployment: missing,
+		},
+		{
+			desc:           "MachineClass seen for the first time",
+			deployment:     newMachineDeploymentWithClass("new"),
+			expectUpdated:  true,
+			expectRecorded: true,
+		},
+		{
+			desc:           "MachineClass changed",
+			deployment:     changed,
+			expectUpdated:  true,
+			expectRollout:  true,
+			expectRecorded: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			r := &ReconcileMachineDeployment{
+				Client:   fake.NewFakeClientWithScheme(scheme.Scheme, class, test.deployment),
+				scheme:   scheme.Scheme,
+				recorder: record.NewFakeRecorder(32),
+			}
+
+			d := &v1alpha1.MachineDeployment{}
+			if err := r.Client.Get(context.Background(), client.ObjectKey{Namespace: "test", Name: test.deployment.Name}, d); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			updated, err := r.syncMachineClassHash(d)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if updated != test.expectUpdated {
+				t.Errorf("expected updated %v, got %v", test.expectUpdated, updated)
+			}
+
+			if err := r.Client.Get(context.Background(), client.ObjectKey{Namespace: "test", Name: test.deployment.Name}, d); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, ok := d.Annotations[dutil.MachineClassHashAnnotation]; ok != test.expectRecorded {
+				t.Errorf("expected hash to be recorded: %v, got %v", test.expectRecorded, ok)
+			}
+			if _, ok := d.Spec.Template.Annotations[dutil.MachineClassHashAnnotation]; ok != test.expectRollout {
+				t.Errorf("expected hash on the machine template: %v, got %v", test.expectRollout, ok)
+			}
+
+			// A second sync is a no-op.
+			if updated, err := r.syncMachineClassHash(d); err != nil || updated {
+				t.Errorf("expected no update, got %v, %v", updated, err)
+			}
+		})
+	}
+}
+
+func TestMachineClassToDeployments(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+
+	class := newMachineClass(`{}`)
+	withClass := newMachineDeploymentWithClass("with-class")
+	otherNamespace := newMachineDeploymentWithClass("other-namespace")
+	otherNamespace.Namespace = "other"
+	withoutClass := &v1alpha1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Name: "without-class", Namespace: "test"}}
+
+	r := &ReconcileMachineDeployment{
+		Client:   fake.NewFakeClientWithScheme(scheme.Scheme, withClass, otherNamespace, withoutClass),
+		scheme:   scheme.Scheme,
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	requests := r.MachineClassToDeployments(handler.MapObject{Meta: class, Object: class})
+	if len(requests) != 1 || requests[0].Name != "with-class" {
+		t.Errorf("expected a single request for %q, got %v", "with-class", requests)
+	}
+}
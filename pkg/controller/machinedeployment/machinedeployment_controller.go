@@ -28,13 +28,15 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
-	"k8s.io/klog"
 	"sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
 	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/controller/predicates"
+	"sigs.k8s.io/cluster-api/pkg/tracing"
 	"sigs.k8s.io/cluster-api/pkg/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
@@ -46,6 +48,11 @@ const controllerName = "machinedeployment_controller"
 var (
 	// controllerKind contains the schema.GroupVersionKind for this controller type.
 	controllerKind = v1alpha1.SchemeGroupVersion.WithKind("MachineDeployment")
+
+	// MaxConcurrentReconciles is the number of MachineDeployments reconciled in parallel.
+	MaxConcurrentReconciles = 1
+
+	log = logf.Log.WithName("machinedeployment-controller")
 )
 
 // ReconcileMachineDeployment reconciles a MachineDeployment object.
@@ -67,13 +74,16 @@ func newReconciler(mgr manager.Manager) *ReconcileMachineDeployment {
 // Add creates a new MachineDeployment Controller and adds it to the Manager with default RBAC.
 func Add(mgr manager.Manager) error {
 	r := newReconciler(mgr)
-	return add(mgr, newReconciler(mgr), r.MachineSetToDeployments)
+	return add(mgr, newReconciler(mgr), r.MachineSetToDeployments, r.MachineClassToDeployments)
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler.
-func add(mgr manager.Manager, r reconcile.Reconciler, mapFn handler.ToRequestsFunc) error {
+func add(mgr manager.Manager, r reconcile.Reconciler, mapFn, classMapFn handler.ToRequestsFunc) error {
 	// Create a new controller.
-	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	c, err := controller.New(controllerName, mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: MaxConcurrentReconciles,
+	})
 	if err != nil {
 		return err
 	}
@@ -82,6 +92,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler, mapFn handler.ToRequestsFu
 	err = c.Watch(&source.Kind{
 		Type: &v1alpha1.MachineDeployment{}},
 		&handler.EnqueueRequestForObject{},
+		predicates.WatchFilter(),
 	)
 	if err != nil {
 		return err
@@ -91,6 +102,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler, mapFn handler.ToRequestsFu
 	err = c.Watch(
 		&source.Kind{Type: &v1alpha1.MachineSet{}},
 		&handler.EnqueueRequestForOwner{OwnerType: &v1alpha1.MachineDeployment{}, IsController: true},
+		predicates.WatchFilter(),
 	)
 	if err != nil {
 		return err
@@ -102,6 +114,17 @@ func add(mgr manager.Manager, r reconcile.Reconciler, mapFn handler.ToRequestsFu
 	err = c.Watch(
 		&source.Kind{Type: &v1alpha1.MachineSet{}},
 		&handler.EnqueueRequestsFromMapFunc{ToRequests: mapFn},
+		predicates.WatchFilter(),
+	)
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to MachineClasses and reconcile the MachineDeployments referencing them.
+	err = c.Watch(
+		&source.Kind{Type: &v1alpha1.MachineClass{}},
+		&handler.EnqueueRequestsFromMapFunc{ToRequests: classMapFn},
+		predicates.WatchFilter(),
 	)
 	if err != nil {
 		return err
@@ -113,10 +136,12 @@ func add(mgr manager.Manager, r reconcile.Reconciler, mapFn handler.ToRequestsFu
 // Reconcile reads that state of the cluster for a MachineDeployment object and makes changes based on the state read
 // and what is in the MachineDeployment.Spec.
 func (r *ReconcileMachineDeployment) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	ctx, span := tracing.StartReconcile(context.Background(), controllerName, request)
+	defer span.End()
+
 	// Fetch the MachineDeployment instance
 	d := &v1alpha1.MachineDeployment{}
-	ctx := context.TODO()
-	if err := r.Get(context.TODO(), request.NamespacedName, d); err != nil {
+	if err := r.Get(ctx, request.NamespacedName, d); err != nil {
 		if apierrors.IsNotFound(err) {
 			// Object not found, return.  Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
@@ -134,7 +159,7 @@ func (r *ReconcileMachineDeployment) Reconcile(request reconcile.Request) (recon
 
 	result, err := r.reconcile(ctx, d)
 	if err != nil {
-		klog.Errorf("Failed to reconcile MachineDeployment %q: %v", request.NamespacedName, err)
+		log.Error(err, "Failed to reconcile MachineDeployment", "machinedeployment", request.Name, "namespace", request.Namespace)
 		r.recorder.Eventf(d, corev1.EventTypeWarning, "ReconcileError", "%v", err)
 	}
 
@@ -142,6 +167,7 @@ func (r *ReconcileMachineDeployment) Reconcile(request reconcile.Request) (recon
 }
 
 func (r *ReconcileMachineDeployment) reconcile(ctx context.Context, d *v1alpha1.MachineDeployment) (reconcile.Result, error) {
+	logger := log.WithValues("machinedeployment", d.Name, "namespace", d.Namespace)
 	v1alpha1.PopulateDefaultsMachineDeployment(d)
 
 	everything := metav1.LabelSelector{}
@@ -149,7 +175,7 @@ func (r *ReconcileMachineDeployment) reconcile(ctx context.Context, d *v1alpha1.
 		if d.Status.ObservedGeneration < d.Generation {
 			d.Status.ObservedGeneration = d.Generation
 			if err := r.Status().Update(context.Background(), d); err != nil {
-				klog.Warningf("Failed to update status for MachineDeployment %q: %v", d.Name, err)
+				logger.Error(err, "Failed to update status")
 				return reconcile.Result{}, err
 			}
 		}
@@ -194,7 +220,7 @@ func (r *ReconcileMachineDeployment) reconcile(ctx context.Context, d *v1alpha1.
 		d.Finalizers = append(d.ObjectMeta.Finalizers, metav1.FinalizerDeleteDependents)
 
 		if err := r.Client.Update(context.Background(), d); err != nil {
-			klog.Infof("Failed to add finalizers to MachineSet %q: %v", d.Name, err)
+			logger.Error(err, "Failed to add finalizers")
 			return reconcile.Result{}, err
 		}
 
@@ -202,6 +228,16 @@ func (r *ReconcileMachineDeployment) reconcile(ctx context.Context, d *v1alpha1.
 		return reconcile.Result{Requeue: true}, nil
 	}
 
+	// Roll out the MachineDeployment when the MachineClass it references changes.
+	updated, err := r.syncMachineClassHash(d)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if updated {
+		// Since recording the hash updates the object return to avoid later update issues
+		return reconcile.Result{Requeue: true}, nil
+	}
+
 	msList, err := r.getMachineSetsForDeployment(d)
 	if err != nil {
 		return reconcile.Result{}, err
@@ -227,7 +263,7 @@ func (r *ReconcileMachineDeployment) reconcile(ctx context.Context, d *v1alpha1.
 // getCluster reuturns the Cluster associated with the MachineDeployment, if any.
 func (r *ReconcileMachineDeployment) getCluster(d *v1alpha1.MachineDeployment) (*v1alpha1.Cluster, error) {
 	if d.Spec.Template.Labels[v1alpha1.MachineClusterLabelName] == "" {
-		klog.Infof("Deployment %q in namespace %q doesn't specify %q label, assuming nil cluster", d.Name, d.Namespace, v1alpha1.MachineClusterLabelName)
+		log.Info("MachineDeployment doesn't specify the cluster label, assuming nil cluster", "machinedeployment", d.Name, "namespace", d.Namespace, "label", v1alpha1.MachineClusterLabelName)
 		return nil, nil
 	}
 
@@ -253,24 +289,25 @@ func (r *ReconcileMachineDeployment) getMachineSetsForDeployment(d *v1alpha1.Mac
 		return nil, err
 	}
 
+	logger := log.WithValues("machinedeployment", d.Name, "namespace", d.Namespace)
 	filtered := make([]*v1alpha1.MachineSet, 0, len(machineSets.Items))
 	for idx := range machineSets.Items {
 		ms := &machineSets.Items[idx]
 
 		selector, err := metav1.LabelSelectorAsSelector(&d.Spec.Selector)
 		if err != nil {
-			klog.Errorf("Skipping MachineSet %q, failed to get label selector from spec selector: %v", ms.Name, err)
+			logger.Error(err, "Skipping MachineSet, failed to get label selector from spec selector", "machineset", ms.Name)
 			continue
 		}
 
 		// If a MachineDeployment with a nil or empty selector creeps in, it should match nothing, not everything.
 		if selector.Empty() {
-			klog.Warningf("Skipping MachineSet %q as the selector is empty", ms.Name)
+			logger.Info("Skipping MachineSet as the selector is empty", "machineset", ms.Name)
 			continue
 		}
 
 		if !selector.Matches(labels.Set(ms.Labels)) {
-			klog.V(4).Infof("Skipping MachineSet %v, label mismatch", ms.Name)
+			logger.V(4).Info("Skipping MachineSet, label mismatch", "machineset", ms.Name)
 			continue
 		}
 
@@ -278,7 +315,7 @@ func (r *ReconcileMachineDeployment) getMachineSetsForDeployment(d *v1alpha1.Mac
 		if metav1.GetControllerOf(ms) == nil {
 			if err := r.adoptOrphan(d, ms); err != nil {
 				r.recorder.Eventf(d, corev1.EventTypeWarning, "FailedAdopt", "Failed to adopt MachineSet %q: %v", ms.Name, err)
-				klog.Warningf("Failed to adopt MachineSet %q into MachineDeployment %q: %v", ms.Name, d.Name, err)
+				logger.Error(err, "Failed to adopt MachineSet", "machineset", ms.Name)
 				continue
 			}
 			r.recorder.Eventf(d, corev1.EventTypeNormal, "SuccessfulAdopt", "Adopted MachineSet %q", ms.Name)
@@ -348,13 +385,13 @@ func (r *ReconcileMachineDeployment) getMachineMapForDeployment(d *v1alpha1.Mach
 // getMachineDeploymentsForMachineSet returns a list of MachineDeployments that could potentially match a MachineSet.
 func (r *ReconcileMachineDeployment) getMachineDeploymentsForMachineSet(ms *v1alpha1.MachineSet) []*v1alpha1.MachineDeployment {
 	if len(ms.Labels) == 0 {
-		klog.Warningf("No machine deployments found for MachineSet %q because it has no labels", ms.Name)
+		log.Info("No MachineDeployments found for MachineSet because it has no labels", "machineset", ms.Name, "namespace", ms.Namespace)
 		return nil
 	}
 
 	dList := &v1alpha1.MachineDeploymentList{}
 	if err := r.Client.List(context.Background(), dList, client.InNamespace(ms.Namespace)); err != nil {
-		klog.Warningf("Failed to list machine deployments: %v", err)
+		log.Error(err, "Failed to list MachineDeployments", "namespace", ms.Namespace)
 		return nil
 	}
 
@@ -385,7 +422,7 @@ func (r *ReconcileMachineDeployment) MachineSetToDeployments(o handler.MapObject
 	key := client.ObjectKey{Namespace: o.Meta.GetNamespace(), Name: o.Meta.GetName()}
 	if err := r.Client.Get(context.Background(), key, ms); err != nil {
 		if !apierrors.IsNotFound(err) {
-			klog.Errorf("Unable to retrieve MachineSet %q for possible MachineDeployment adoption: %v", key, err)
+			log.Error(err, "Unable to retrieve MachineSet for possible MachineDeployment adoption", "machineset", key.Name, "namespace", key.Namespace)
 		}
 		return nil
 	}
@@ -400,7 +437,7 @@ func (r *ReconcileMachineDeployment) MachineSetToDeployments(o handler.MapObject
 
 	mds := r.getMachineDeploymentsForMachineSet(ms)
 	if len(mds) == 0 {
-		klog.V(4).Infof("Found no machine set for machine: %v", ms.Name)
+		log.V(4).Info("Found no MachineDeployment for MachineSet", "machineset", ms.Name, "namespace", ms.Namespace)
 		return nil
 	}
 
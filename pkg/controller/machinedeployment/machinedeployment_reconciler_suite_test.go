@@ -17,7 +17,7 @@ limitations under the License.
 package machinedeployment
 
 import (
-	"log"
+	stdlog "log"
 	"os"
 	"path/filepath"
 	"testing"
@@ -40,7 +40,7 @@ func TestMain(m *testing.M) {
 
 	var err error
 	if cfg, err = t.Start(); err != nil {
-		log.Fatal(err)
+		stdlog.Fatal(err)
 	}
 
 	code := m.Run()
@@ -80,7 +80,7 @@ func TestReconcile(t *testing.T) {
 
 	r := newReconciler(mgr)
 	recFn, requests, errors := SetupTestReconcile(r)
-	if err := add(mgr, recFn, r.MachineSetToDeployments); err != nil {
+	if err := add(mgr, recFn, r.MachineSetToDeployments, r.MachineClassToDeployments); err != nil {
 		t.Errorf("error adding controller to manager: %v", err)
 	}
 	defer close(StartTestManager(mgr, t))
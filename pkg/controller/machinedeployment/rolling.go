@@ -21,7 +21,6 @@ import (
 
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/klog"
 	"k8s.io/utils/integer"
 	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
 	dutil "sigs.k8s.io/cluster-api/pkg/controller/machinedeployment/util"
@@ -114,7 +113,7 @@ func (r *ReconcileMachineDeployment) reconcileOldMachineSets(allMSs []*v1alpha1.
 	}
 
 	allMachinesCount := dutil.GetReplicaCountForMachineSets(allMSs)
-	klog.V(4).Infof("New machine set %s/%s has %d available machines.", newMS.Namespace, newMS.Name, newMS.Status.AvailableReplicas)
+	log.V(4).Info("New MachineSet has available Machines", "machineset", newMS.Name, "namespace", newMS.Namespace, "available", newMS.Status.AvailableReplicas)
 	maxUnavailable := dutil.MaxUnavailable(*deployment)
 
 	// Check if we can scale down. We can scale down in the following 2 cases:
@@ -161,7 +160,7 @@ func (r *ReconcileMachineDeployment) reconcileOldMachineSets(allMSs []*v1alpha1.
 		return nil
 	}
 
-	klog.V(4).Infof("Cleaned up unhealthy replicas from old MachineSets by %d", cleanupCount)
+	log.V(4).Info("Cleaned up unhealthy replicas from old MachineSets", "machinedeployment", deployment.Name, "namespace", deployment.Namespace, "count", cleanupCount)
 
 	// Scale down old machine sets, need check maxUnavailable to ensure we can scale down
 	allMSs = append(oldMSs, newMS)
@@ -170,7 +169,7 @@ func (r *ReconcileMachineDeployment) reconcileOldMachineSets(allMSs []*v1alpha1.
 		return err
 	}
 
-	klog.V(4).Infof("Scaled down old MachineSets of deployment %s by %d", deployment.Name, scaledDownCount)
+	log.V(4).Info("Scaled down old MachineSets", "machinedeployment", deployment.Name, "namespace", deployment.Namespace, "count", scaledDownCount)
 	return nil
 }
 
@@ -199,7 +198,7 @@ func (r *ReconcileMachineDeployment) cleanupUnhealthyReplicas(oldMSs []*v1alpha1
 		}
 
 		oldMSAvailableReplicas := targetMS.Status.AvailableReplicas
-		klog.V(4).Infof("Found %d available machines in old MS %s/%s", oldMSAvailableReplicas, targetMS.Namespace, targetMS.Name)
+		log.V(4).Info("Found available Machines in old MachineSet", "machineset", targetMS.Name, "namespace", targetMS.Namespace, "available", oldMSAvailableReplicas)
 		if oldMSReplicas == oldMSAvailableReplicas {
 			// no unhealthy replicas found, no scaling required.
 			continue
@@ -243,7 +242,7 @@ func (r *ReconcileMachineDeployment) scaleDownOldMachineSetsForRollingUpdate(all
 		return 0, nil
 	}
 
-	klog.V(4).Infof("Found %d available machines in deployment %s, scaling down old MSes", availableMachineCount, deployment.Name)
+	log.V(4).Info("Found available Machines, scaling down old MachineSets", "machinedeployment", deployment.Name, "namespace", deployment.Namespace, "available", availableMachineCount)
 
 	sort.Sort(dutil.MachineSetsByCreationTimestamp(oldMSs))
 
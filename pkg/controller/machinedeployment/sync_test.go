@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinedeployment
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSyncPaused(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+	labels := map[string]string{"app": "foo"}
+
+	d := &v1alpha1.MachineDeployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "MachineDeployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test", UID: "foo-uid"},
+		Spec: v1alpha1.MachineDeploymentSpec{
+			Replicas: int32Ptr(5),
+			Selector: metav1.LabelSelector{MatchLabels: labels},
+			Paused:   true,
+			Strategy: &v1alpha1.MachineDeploymentStrategy{
+				Type: common.RollingUpdateMachineDeploymentStrategyType,
+				RollingUpdate: &v1alpha1.MachineRollingUpdateDeployment{
+					MaxSurge:       intstrPtr(1),
+					MaxUnavailable: intstrPtr(0),
+				},
+			},
+			Template: v1alpha1.MachineTemplateSpec{
+				ObjectMeta: v1alpha1.ObjectMeta{Labels: labels},
+				Spec:       v1alpha1.MachineSpec{Versions: v1alpha1.MachineVersionInfo{Kubelet: "1.15.0"}},
+			},
+		},
+	}
+	// The MachineSet of the previous template, before the template was changed.
+	ms := &v1alpha1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "foo-old",
+			Namespace:       "test",
+			Labels:          labels,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(d, controllerKind)},
+		},
+		Spec: v1alpha1.MachineSetSpec{
+			Replicas: int32Ptr(3),
+			Selector: metav1.LabelSelector{MatchLabels: labels},
+			Template: v1alpha1.MachineTemplateSpec{
+				ObjectMeta: v1alpha1.ObjectMeta{Labels: labels},
+				Spec:       v1alpha1.MachineSpec{Versions: v1alpha1.MachineVersionInfo{Kubelet: "1.14.0"}},
+			},
+		},
+	}
+
+	r := &ReconcileMachineDeployment{
+		Client:   fake.NewFakeClientWithScheme(scheme.Scheme, d, ms),
+		scheme:   scheme.Scheme,
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	msList, err := r.getMachineSetsForDeployment(d)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	machineMap, err := r.getMachineMapForDeployment(d, msList)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.sync(d, msList, machineMap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The template change is not rolled out, but the deployment is scaled.
+	machineSets := &v1alpha1.MachineSetList{}
+	if err := r.List(context.Background(), machineSets, client.InNamespace("test")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(machineSets.Items) != 1 {
+		t.Fatalf("expected no new MachineSet to be created, got %d MachineSets", len(machineSets.Items))
+	}
+	if got := machineSets.Items[0]; got.Name != "foo-old" || *got.Spec.Replicas != 5 {
+		t.Errorf("expected MachineSet foo-old to be scaled to 5 replicas, got %s with %d", got.Name, *got.Spec.Replicas)
+	}
+}
@@ -31,10 +31,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
-	"k8s.io/klog"
 	"k8s.io/utils/integer"
 	"sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
 	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 const (
@@ -52,6 +52,10 @@ const (
 	// is machinedeployment.spec.replicas + maxSurge. Used by the underlying machine sets to estimate their
 	// proportions in case the deployment has surge replicas.
 	MaxReplicasAnnotation = "machinedeployment.clusters.k8s.io/max-replicas"
+	// MachineClassHashAnnotation is the hash of the MachineClass referenced by a machine deployment's
+	// machine template. It is recorded on the deployment, and set on its machine template when the
+	// MachineClass changes to roll the machines out.
+	MachineClassHashAnnotation = "machinedeployment.clusters.k8s.io/machine-class-hash"
 
 	// FailedMSCreateReason is added in a machine deployment when it cannot create a new machine set.
 	FailedMSCreateReason = "MachineSetCreateError"
@@ -71,6 +75,8 @@ const (
 	MinimumReplicasUnavailable = "MinimumReplicasUnavailable"
 )
 
+var log = logf.Log.WithName("machinedeployment-util")
+
 // MachineSetsByCreationTimestamp sorts a list of MachineSet by creation timestamp, using their names as a tie breaker.
 type MachineSetsByCreationTimestamp []*v1alpha1.MachineSet
 
@@ -130,7 +136,7 @@ func MaxRevision(allMSs []*v1alpha1.MachineSet) int64 {
 	for _, ms := range allMSs {
 		if v, err := Revision(ms); err != nil {
 			// Skip the machine sets when it failed to parse their revision information
-			klog.V(4).Infof("Error: %v. Couldn't parse revision for machine set %#v, deployment controller will skip it when reconciling revisions.", err, ms)
+			log.V(4).Info("Couldn't parse revision for MachineSet, deployment controller will skip it when reconciling revisions", "machineset", ms.Name, "namespace", ms.Namespace, "error", err.Error())
 		} else if v > max {
 			max = v
 		}
@@ -157,6 +163,11 @@ var annotationsToSkip = map[string]bool{
 	RevisionHistoryAnnotation:      true,
 	DesiredReplicasAnnotation:      true,
 	MaxReplicasAnnotation:          true,
+	MachineClassHashAnnotation:     true,
+
+	v1alpha1.ClusterctlVersionAnnotation:   true,
+	v1alpha1.ClusterctlOperationAnnotation: true,
+	v1alpha1.ClusterctlTimestampAnnotation: true,
 }
 
 // skipCopyAnnotation returns true if we should skip copying the annotation with the given annotation key
@@ -203,7 +214,7 @@ func getIntFromAnnotation(ms *v1alpha1.MachineSet, annotationKey string) (int32,
 	}
 	intValue, err := strconv.Atoi(annotationValue)
 	if err != nil {
-		klog.V(2).Infof("Cannot convert the value %q with annotation key %q for the machine set %q", annotationValue, annotationKey, ms.Name)
+		log.V(2).Info("Cannot convert the value of the annotation", "value", annotationValue, "annotation", annotationKey, "machineset", ms.Name, "namespace", ms.Namespace)
 		return int32(0), false
 	}
 	return int32(intValue), true
@@ -226,7 +237,7 @@ func SetNewMachineSetAnnotations(deployment *v1alpha1.MachineDeployment, newMS *
 	oldRevisionInt, err := strconv.ParseInt(oldRevision, 10, 64)
 	if err != nil {
 		if oldRevision != "" {
-			klog.Warningf("Updating machine set revision OldRevision not int %s", err)
+			log.Error(err, "Updating MachineSet revision, OldRevision not int", "machineset", newMS.Name, "namespace", newMS.Namespace)
 			return false
 		}
 		//If the MS annotation is empty then initialise it to 0
@@ -234,13 +245,13 @@ func SetNewMachineSetAnnotations(deployment *v1alpha1.MachineDeployment, newMS *
 	}
 	newRevisionInt, err := strconv.ParseInt(newRevision, 10, 64)
 	if err != nil {
-		klog.Warningf("Updating machine set revision NewRevision not int %s", err)
+		log.Error(err, "Updating MachineSet revision, NewRevision not int", "machineset", newMS.Name, "namespace", newMS.Namespace)
 		return false
 	}
 	if oldRevisionInt < newRevisionInt {
 		newMS.Annotations[RevisionAnnotation] = newRevision
 		annotationChanged = true
-		klog.V(4).Infof("Updating machine set %q revision to %s", newMS.Name, newRevision)
+		log.V(4).Info("Updating MachineSet revision", "machineset", newMS.Name, "namespace", newMS.Namespace, "revision", newRevision)
 	}
 	// If a revision annotation already existed and this machine set was updated with a new revision
 	// then that means we are rolling back to this machine set. We need to preserve the old revisions
@@ -692,3 +703,11 @@ func ComputeHash(template *v1alpha1.MachineTemplateSpec) uint32 {
 
 	return machineTemplateSpecHasher.Sum32()
 }
+
+// ComputeMachineClassHash returns a hash of the provider configuration of a MachineClass.
+func ComputeMachineClassHash(class *v1alpha1.MachineClass) uint32 {
+	machineClassHasher := fnv.New32a()
+	machineClassHasher.Write(class.ProviderSpec.Raw)
+
+	return machineClassHasher.Sum32()
+}
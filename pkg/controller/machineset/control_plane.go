@@ -0,0 +1,71 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machineset
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// waitsForControlPlane returns true if the MachineSet creates worker Machines for a cluster whose
+// control plane is not initialized yet, so that they would fail to join it.
+func waitsForControlPlane(ms *v1alpha1.MachineSet, cluster *v1alpha1.Cluster) bool {
+	if cluster == nil || util.IsControlPlaneMachineSpec(&ms.Spec.Template.Spec) {
+		return false
+	}
+	for _, condition := range cluster.Status.Conditions {
+		if condition.Type == v1alpha1.ControlPlaneInitializedCondition {
+			return condition.Status != corev1.ConditionTrue
+		}
+	}
+	// Clusters without the condition have no control plane Machines to wait for.
+	return false
+}
+
+// ClusterToMachineSets is a handler.ToRequestsFunc to be used to enqueue requests for
+// reconciliation of the MachineSets creating Machines for a Cluster.
+func (r *ReconcileMachineSet) ClusterToMachineSets(o handler.MapObject) []reconcile.Request {
+	cluster, ok := o.Object.(*v1alpha1.Cluster)
+	if !ok {
+		return nil
+	}
+
+	msList := &v1alpha1.MachineSetList{}
+	if err := r.List(context.Background(), msList, client.InNamespace(cluster.Namespace)); err != nil {
+		log.Error(err, "Failed to list MachineSets", "cluster", cluster.Name, "namespace", cluster.Namespace)
+		return nil
+	}
+
+	var result []reconcile.Request
+	for i := range msList.Items {
+		ms := &msList.Items[i]
+		if ms.Spec.Template.Labels[v1alpha1.MachineClusterLabelName] != cluster.Name {
+			continue
+		}
+		result = append(result, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: ms.Namespace, Name: ms.Name},
+		})
+	}
+	return result
+}
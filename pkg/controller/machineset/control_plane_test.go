@@ -0,0 +1,94 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machineset
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWaitsForControlPlane(t *testing.T) {
+	controlPlane := &v1alpha1.MachineSet{}
+	controlPlane.Spec.Template.Spec.Versions.ControlPlane = "1.15.3"
+	worker := &v1alpha1.MachineSet{}
+
+	clusterWithCondition := func(status corev1.ConditionStatus) *v1alpha1.Cluster {
+		return &v1alpha1.Cluster{Status: v1alpha1.ClusterStatus{Conditions: []v1alpha1.ClusterCondition{{
+			Type:   v1alpha1.ControlPlaneInitializedCondition,
+			Status: status,
+		}}}}
+	}
+
+	tests := []struct {
+		desc    string
+		ms      *v1alpha1.MachineSet
+		cluster *v1alpha1.Cluster
+		expect  bool
+	}{
+		{desc: "workers of uninitialized control plane", ms: worker, cluster: clusterWithCondition(corev1.ConditionFalse), expect: true},
+		{desc: "workers of initialized control plane", ms: worker, cluster: clusterWithCondition(corev1.ConditionTrue)},
+		{desc: "workers without condition", ms: worker, cluster: &v1alpha1.Cluster{}},
+		{desc: "workers without cluster", ms: worker},
+		{desc: "control plane of uninitialized control plane", ms: controlPlane, cluster: clusterWithCondition(corev1.ConditionFalse)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if actual := waitsForControlPlane(test.ms, test.cluster); actual != test.expect {
+				t.Errorf("expected %v, got %v", test.expect, actual)
+			}
+		})
+	}
+}
+
+func TestSyncReplicasWaitsForControlPlane(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+
+	replicas := int32(2)
+	ms := &v1alpha1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "workers", Namespace: "default"},
+		Spec:       v1alpha1.MachineSetSpec{Replicas: &replicas},
+	}
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Status: v1alpha1.ClusterStatus{Conditions: []v1alpha1.ClusterCondition{{
+			Type:   v1alpha1.ControlPlaneInitializedCondition,
+			Status: corev1.ConditionFalse,
+		}}},
+	}
+	recorder := record.NewFakeRecorder(32)
+	r := &ReconcileMachineSet{Client: fake.NewFakeClientWithScheme(scheme.Scheme, ms), scheme: scheme.Scheme, recorder: recorder}
+
+	if err := r.syncReplicas(ms, cluster, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	close(recorder.Events)
+	var events []string
+	for e := range recorder.Events {
+		events = append(events, e)
+	}
+	if len(events) != 1 || !strings.Contains(events[0], "WaitingForControlPlane") || !strings.Contains(events[0], string(v1alpha1.ControlPlaneInitializedCondition)) {
+		t.Errorf("expected a single WaitingForControlPlane event naming %s, got %v", v1alpha1.ControlPlaneInitializedCondition, events)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machineset
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterv1alpha1 "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+// deleteFailedMachines deletes the Machines of the MachineSet which have been failed for longer
+// than the grace period set with the FailedMachineGracePeriodAnnotation, so that they are replaced.
+// It returns the Machines left, and how long until the next failed Machine reaches the grace
+// period, or zero if there is none.
+func (r *ReconcileMachineSet) deleteFailedMachines(ctx context.Context, ms *clusterv1alpha1.MachineSet, machines []*clusterv1alpha1.Machine) ([]*clusterv1alpha1.Machine, time.Duration, error) {
+	value, ok := ms.Annotations[clusterv1alpha1.FailedMachineGracePeriodAnnotation]
+	if !ok {
+		return machines, 0, nil
+	}
+	gracePeriod, err := time.ParseDuration(value)
+	if err != nil || gracePeriod < 0 {
+		log.Info("Ignoring invalid failed Machine grace period", "machineset", ms.Name, "namespace", ms.Namespace, "value", value)
+		r.recorder.Eventf(ms, corev1.EventTypeWarning, "InvalidFailedMachineGracePeriod", "Invalid %s annotation %q", clusterv1alpha1.FailedMachineGracePeriodAnnotation, value)
+		return machines, 0, nil
+	}
+
+	now := time.Now()
+	var requeueAfter time.Duration
+	remaining := make([]*clusterv1alpha1.Machine, 0, len(machines))
+	for _, machine := range machines {
+		failed := machine.Status.ErrorReason != nil || machine.Status.ErrorMessage != nil
+		value, annotated := machine.Annotations[clusterv1alpha1.MachineFailedSinceAnnotation]
+		since, parseErr := time.Parse(time.RFC3339, value)
+
+		switch {
+		case !failed:
+			if annotated {
+				delete(machine.Annotations, clusterv1alpha1.MachineFailedSinceAnnotation)
+				if err := r.Client.Update(ctx, machine); err != nil {
+					return nil, 0, errors.Wrapf(err, "failed to update Machine %q", machine.Name)
+				}
+			}
+
+		case parseErr != nil:
+			// The Machine was not seen failed before, or the annotation was tampered with.
+			if machine.Annotations == nil {
+				machine.Annotations = map[string]string{}
+			}
+			machine.Annotations[clusterv1alpha1.MachineFailedSinceAnnotation] = now.UTC().Format(time.RFC3339)
+			if err := r.Client.Update(ctx, machine); err != nil {
+				return nil, 0, errors.Wrapf(err, "failed to update Machine %q", machine.Name)
+			}
+			requeueAfter = minRequeueAfter(requeueAfter, gracePeriod)
+
+		case now.Sub(since) < gracePeriod:
+			requeueAfter = minRequeueAfter(requeueAfter, gracePeriod-now.Sub(since))
+
+		default:
+			if err := r.Client.Delete(ctx, machine); err != nil && !apierrors.IsNotFound(err) {
+				r.recorder.Eventf(ms, corev1.EventTypeWarning, "FailedDelete", "Failed to delete failed machine %q: %v", machine.Name, err)
+				return nil, 0, errors.Wrapf(err, "failed to delete Machine %q", machine.Name)
+			}
+			log.Info("Deleted failed Machine", "machineset", ms.Name, "namespace", ms.Namespace, "machine", machine.Name, "failedSince", since)
+			r.recorder.Eventf(ms, corev1.EventTypeNormal, "SuccessfulDelete", "Deleted machine %q failed for more than %v", machine.Name, gracePeriod)
+			continue
+		}
+
+		remaining = append(remaining, machine)
+	}
+	return remaining, requeueAfter, nil
+}
+
+// minRequeueAfter returns the shortest of two non-zero durations.
+func minRequeueAfter(a, b time.Duration) time.Duration {
+	if a == 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
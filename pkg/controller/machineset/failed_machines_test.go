@@ -0,0 +1,139 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machineset
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDeleteFailedMachines(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+	errorReason := common.CreateMachineError
+	now := time.Now()
+
+	testCases := []struct {
+		name         string
+		gracePeriod  string
+		failed       bool
+		failedSince  string
+		deleted      bool
+		annotated    bool
+		requeueAfter time.Duration
+	}{
+		{
+			name:   "no policy",
+			failed: true,
+		},
+		{
+			name:        "invalid policy",
+			gracePeriod: "soon",
+			failed:      true,
+		},
+		{
+			name:        "healthy machine",
+			gracePeriod: "10m",
+		},
+		{
+			name:        "recovered machine",
+			gracePeriod: "10m",
+			failedSince: now.Add(-time.Hour).Format(time.RFC3339),
+		},
+		{
+			name:         "newly failed machine",
+			gracePeriod:  "10m",
+			failed:       true,
+			annotated:    true,
+			requeueAfter: 10 * time.Minute,
+		},
+		{
+			name:         "failed machine within grace period",
+			gracePeriod:  "10m",
+			failed:       true,
+			failedSince:  now.Add(-5 * time.Minute).Format(time.RFC3339),
+			annotated:    true,
+			requeueAfter: 5 * time.Minute,
+		},
+		{
+			name:        "failed machine past grace period",
+			gracePeriod: "10m",
+			failed:      true,
+			failedSince: now.Add(-15 * time.Minute).Format(time.RFC3339),
+			deleted:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &v1alpha1.MachineSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "default"},
+			}
+			if tc.gracePeriod != "" {
+				ms.Annotations = map[string]string{v1alpha1.FailedMachineGracePeriodAnnotation: tc.gracePeriod}
+			}
+			machine := &v1alpha1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "m", Namespace: "default"},
+			}
+			if tc.failed {
+				machine.Status.ErrorReason = &errorReason
+			}
+			if tc.failedSince != "" {
+				machine.Annotations = map[string]string{v1alpha1.MachineFailedSinceAnnotation: tc.failedSince}
+			}
+
+			c := fake.NewFakeClientWithScheme(scheme.Scheme, machine)
+			r := &ReconcileMachineSet{Client: c, recorder: record.NewFakeRecorder(32)}
+
+			remaining, requeueAfter, err := r.deleteFailedMachines(context.Background(), ms, []*v1alpha1.Machine{machine})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (len(remaining) == 0) != tc.deleted {
+				t.Errorf("expected the machine to be deleted: %v, got %d machines left", tc.deleted, len(remaining))
+			}
+			// Allow for the time elapsed since the test started.
+			if requeueAfter > tc.requeueAfter || requeueAfter < tc.requeueAfter-time.Minute {
+				t.Errorf("expected to requeue after %v, got %v", tc.requeueAfter, requeueAfter)
+			}
+
+			got := &v1alpha1.Machine{}
+			err = c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "m"}, got)
+			if tc.deleted {
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("expected the machine to be deleted, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, ok := got.Annotations[v1alpha1.MachineFailedSinceAnnotation]; ok != tc.annotated {
+				t.Errorf("expected the machine to be annotated: %v, got annotations %v", tc.annotated, got.Annotations)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machineset
+
+import (
+	"sort"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/util"
+)
+
+// spreadsAcrossFailureDomains returns true if the Machines of the MachineSet are control plane
+// Machines to be spread across the failure domains of the cluster.
+func spreadsAcrossFailureDomains(ms *v1alpha1.MachineSet, cluster *v1alpha1.Cluster) bool {
+	if cluster == nil || len(cluster.Status.FailureDomains) == 0 {
+		return false
+	}
+	return util.IsControlPlaneMachineSpec(&ms.Spec.Template.Spec) && ms.Spec.Template.Spec.FailureDomain == nil
+}
+
+// countByFailureDomain returns the number of machines in each of the failure domains.
+// Machines in unknown failure domains are not counted.
+func countByFailureDomain(failureDomains []string, machines []*v1alpha1.Machine) map[string]int {
+	counts := make(map[string]int, len(failureDomains))
+	for _, fd := range failureDomains {
+		counts[fd] = 0
+	}
+	for _, m := range machines {
+		if m.Spec.FailureDomain == nil {
+			continue
+		}
+		if _, ok := counts[*m.Spec.FailureDomain]; ok {
+			counts[*m.Spec.FailureDomain]++
+		}
+	}
+	return counts
+}
+
+// leastUsedFailureDomain returns the failure domain with the fewest machines.
+// Ties are broken by the order of the failure domains in the cluster status.
+func leastUsedFailureDomain(failureDomains []string, machines []*v1alpha1.Machine) string {
+	counts := countByFailureDomain(failureDomains, machines)
+	least := failureDomains[0]
+	for _, fd := range failureDomains[1:] {
+		if counts[fd] < counts[least] {
+			least = fd
+		}
+	}
+	return least
+}
+
+// getMachinesToDeleteSpread picks diff machines to delete, always from the failure domain
+// with the most machines. Machines outside of known failure domains are deleted first and,
+// within a failure domain, machines are picked according to the delete priority.
+func getMachinesToDeleteSpread(failureDomains []string, machines []*v1alpha1.Machine, diff int, fun deletePriorityFunc) []*v1alpha1.Machine {
+	if diff >= len(machines) {
+		return machines
+	} else if diff <= 0 {
+		return []*v1alpha1.Machine{}
+	}
+
+	candidates := make([]*v1alpha1.Machine, len(machines))
+	copy(candidates, machines)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return fun(candidates[j]) < fun(candidates[i]) // high to low
+	})
+
+	toDelete := make([]*v1alpha1.Machine, 0, diff)
+	for len(toDelete) < diff {
+		counts := countByFailureDomain(failureDomains, candidates)
+		index := -1
+		for i, m := range candidates {
+			if m.Spec.FailureDomain == nil {
+				index = i
+				break
+			}
+			if _, ok := counts[*m.Spec.FailureDomain]; !ok {
+				index = i
+				break
+			}
+			if index < 0 || counts[*m.Spec.FailureDomain] > counts[*candidates[index].Spec.FailureDomain] {
+				index = i
+			}
+		}
+		toDelete = append(toDelete, candidates[index])
+		candidates = append(candidates[:index], candidates[index+1:]...)
+	}
+	return toDelete
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machineset
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+var failureDomains = []string{"us-east-1a", "us-east-1b", "us-east-1c"}
+
+func machineInFailureDomain(name, failureDomain string) *v1alpha1.Machine {
+	m := &v1alpha1.Machine{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if failureDomain != "" {
+		m.Spec.FailureDomain = &failureDomain
+	}
+	return m
+}
+
+func TestSpreadsAcrossFailureDomains(t *testing.T) {
+	controlPlane := &v1alpha1.MachineSet{}
+	controlPlane.Spec.Template.Spec.Versions.ControlPlane = "1.15.3"
+	worker := &v1alpha1.MachineSet{}
+	pinned := controlPlane.DeepCopy()
+	pinned.Spec.Template.Spec.FailureDomain = &failureDomains[0]
+
+	cluster := &v1alpha1.Cluster{Status: v1alpha1.ClusterStatus{FailureDomains: failureDomains}}
+
+	tests := []struct {
+		desc    string
+		ms      *v1alpha1.MachineSet
+		cluster *v1alpha1.Cluster
+		expect  bool
+	}{
+		{desc: "control plane with failure domains", ms: controlPlane, cluster: cluster, expect: true},
+		{desc: "control plane without cluster", ms: controlPlane},
+		{desc: "control plane without failure domains", ms: controlPlane, cluster: &v1alpha1.Cluster{}},
+		{desc: "control plane pinned to a failure domain", ms: pinned, cluster: cluster},
+		{desc: "workers", ms: worker, cluster: cluster},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if actual := spreadsAcrossFailureDomains(test.ms, test.cluster); actual != test.expect {
+				t.Errorf("expected %v, got %v", test.expect, actual)
+			}
+		})
+	}
+}
+
+func TestLeastUsedFailureDomain(t *testing.T) {
+	tests := []struct {
+		desc     string
+		machines []*v1alpha1.Machine
+		expect   string
+	}{
+		{
+			desc:   "no machines",
+			expect: "us-east-1a",
+		},
+		{
+			desc: "one empty failure domain",
+			machines: []*v1alpha1.Machine{
+				machineInFailureDomain("a", "us-east-1a"),
+				machineInFailureDomain("b", "us-east-1c"),
+			},
+			expect: "us-east-1b",
+		},
+		{
+			desc: "machines in unknown failure domains",
+			machines: []*v1alpha1.Machine{
+				machineInFailureDomain("a", "us-east-1a"),
+				machineInFailureDomain("b", "us-east-1b"),
+				machineInFailureDomain("c", "us-west-1a"),
+				machineInFailureDomain("d", ""),
+			},
+			expect: "us-east-1c",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if actual := leastUsedFailureDomain(failureDomains, test.machines); actual != test.expect {
+				t.Errorf("expected %q, got %q", test.expect, actual)
+			}
+		})
+	}
+}
+
+func TestGetMachinesToDeleteSpread(t *testing.T) {
+	a1 := machineInFailureDomain("a1", "us-east-1a")
+	a2 := machineInFailureDomain("a2", "us-east-1a")
+	b1 := machineInFailureDomain("b1", "us-east-1b")
+	b2 := machineInFailureDomain("b2", "us-east-1b")
+	c1 := machineInFailureDomain("c1", "us-east-1c")
+	unknown := machineInFailureDomain("unknown", "us-west-1a")
+	deleteMe := machineInFailureDomain("delete-me", "us-east-1c")
+	deleteMe.Annotations = map[string]string{DeleteNodeAnnotation: "yes"}
+
+	tests := []struct {
+		desc     string
+		machines []*v1alpha1.Machine
+		diff     int
+		expect   []*v1alpha1.Machine
+	}{
+		{
+			desc:     "diff=0",
+			machines: []*v1alpha1.Machine{a1, b1},
+			diff:     0,
+			expect:   []*v1alpha1.Machine{},
+		},
+		{
+			desc:     "from over-represented failure domain",
+			machines: []*v1alpha1.Machine{a1, b1, b2, c1},
+			diff:     1,
+			expect:   []*v1alpha1.Machine{b1},
+		},
+		{
+			desc:     "from over-represented failure domains in turn",
+			machines: []*v1alpha1.Machine{a1, a2, b1, b2, c1},
+			diff:     2,
+			expect:   []*v1alpha1.Machine{a1, b1},
+		},
+		{
+			desc:     "unknown failure domain first",
+			machines: []*v1alpha1.Machine{a1, a2, unknown},
+			diff:     1,
+			expect:   []*v1alpha1.Machine{unknown},
+		},
+		{
+			desc:     "delete priority within a failure domain",
+			machines: []*v1alpha1.Machine{a1, b1, c1, deleteMe},
+			diff:     1,
+			expect:   []*v1alpha1.Machine{deleteMe},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			actual := getMachinesToDeleteSpread(failureDomains, test.machines, test.diff, randomDeletePolicy)
+			if !reflect.DeepEqual(actual, test.expect) {
+				t.Errorf("expected %v, got %v", test.expect, actual)
+			}
+		})
+	}
+}
@@ -21,21 +21,20 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/klog"
 	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func (c *ReconcileMachineSet) getMachineSetsForMachine(m *v1alpha1.Machine) []*v1alpha1.MachineSet {
 	if len(m.Labels) == 0 {
-		klog.Warningf("No machine sets found for Machine %v because it has no labels", m.Name)
+		log.Info("No MachineSets found for Machine because it has no labels", "machine", m.Name, "namespace", m.Namespace)
 		return nil
 	}
 
 	msList := &v1alpha1.MachineSetList{}
 	err := c.Client.List(context.Background(), msList, client.InNamespace(m.Namespace))
 	if err != nil {
-		klog.Errorf("Failed to list machine sets, %v", err)
+		log.Error(err, "Failed to list MachineSets", "namespace", m.Namespace)
 		return nil
 	}
 
@@ -53,18 +52,18 @@ func (c *ReconcileMachineSet) getMachineSetsForMachine(m *v1alpha1.Machine) []*v
 func hasMatchingLabels(machineSet *v1alpha1.MachineSet, machine *v1alpha1.Machine) bool {
 	selector, err := metav1.LabelSelectorAsSelector(&machineSet.Spec.Selector)
 	if err != nil {
-		klog.Warningf("unable to convert selector: %v", err)
+		log.Error(err, "Failed to convert selector", "machineset", machineSet.Name, "namespace", machineSet.Namespace)
 		return false
 	}
 
 	// If a deployment with a nil or empty selector creeps in, it should match nothing, not everything.
 	if selector.Empty() {
-		klog.V(2).Infof("%v machineset has empty selector", machineSet.Name)
+		log.V(2).Info("MachineSet has empty selector", "machineset", machineSet.Name, "namespace", machineSet.Namespace)
 		return false
 	}
 
 	if !selector.Matches(labels.Set(machine.Labels)) {
-		klog.V(4).Infof("%v machine has mismatch labels", machine.Name)
+		log.V(4).Info("Machine has mismatched labels", "machine", machine.Name, "namespace", machine.Namespace)
 		return false
 	}
 
@@ -30,12 +30,15 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
-	"k8s.io/klog"
 	clusterv1alpha1 "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/controller/predicates"
+	"sigs.k8s.io/cluster-api/pkg/controller/remote"
+	"sigs.k8s.io/cluster-api/pkg/tracing"
 	"sigs.k8s.io/cluster-api/pkg/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
@@ -54,28 +57,41 @@ var (
 	// stateConfirmationInterval is the amount of time between polling for the desired state.
 	// The polling is against a local memory cache.
 	stateConfirmationInterval = 100 * time.Millisecond
+
+	// MaxConcurrentReconciles is the number of MachineSets reconciled in parallel.
+	MaxConcurrentReconciles = 1
+
+	log = logf.Log.WithName("machineset-controller")
 )
 
 // Add creates a new MachineSet Controller and adds it to the Manager with default RBAC.
 // The Manager will set fields on the Controller and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	r := newReconciler(mgr)
-	return add(mgr, r, r.MachineToMachineSets)
+	tracker, err := remote.ClusterCacheTrackerFor(mgr)
+	if err != nil {
+		return err
+	}
+	r := newReconciler(mgr, tracker)
+	return add(mgr, r, r.MachineToMachineSets, r.ClusterToMachineSets)
 }
 
 // newReconciler returns a new reconcile.Reconciler.
-func newReconciler(mgr manager.Manager) *ReconcileMachineSet {
+func newReconciler(mgr manager.Manager, tracker *remote.ClusterCacheTracker) *ReconcileMachineSet {
 	return &ReconcileMachineSet{
 		Client:   mgr.GetClient(),
 		scheme:   mgr.GetScheme(),
 		recorder: mgr.GetEventRecorderFor(controllerName),
+		tracker:  tracker,
 	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler.
-func add(mgr manager.Manager, r reconcile.Reconciler, mapFn handler.ToRequestsFunc) error {
+func add(mgr manager.Manager, r reconcile.Reconciler, mapFn, clusterMapFn handler.ToRequestsFunc) error {
 	// Create a new controller.
-	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	c, err := controller.New(controllerName, mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: MaxConcurrentReconciles,
+	})
 	if err != nil {
 		return err
 	}
@@ -84,6 +100,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler, mapFn handler.ToRequestsFu
 	err = c.Watch(
 		&source.Kind{Type: &clusterv1alpha1.MachineSet{}},
 		&handler.EnqueueRequestForObject{},
+		predicates.WatchFilter(),
 	)
 	if err != nil {
 		return err
@@ -93,6 +110,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler, mapFn handler.ToRequestsFu
 	err = c.Watch(
 		&source.Kind{Type: &clusterv1alpha1.Machine{}},
 		&handler.EnqueueRequestForOwner{IsController: true, OwnerType: &clusterv1alpha1.MachineSet{}},
+		predicates.WatchFilter(),
 	)
 	if err != nil {
 		return err
@@ -101,9 +119,21 @@ func add(mgr manager.Manager, r reconcile.Reconciler, mapFn handler.ToRequestsFu
 	// Watch for changes to Machines using a mapping function to MachineSets.
 	// This watcher is required for use cases like adoption. In case a Machine doesn't have
 	// a controller reference, it'll look for potential matching MachineSet to reconcile.
-	return c.Watch(
+	err = c.Watch(
 		&source.Kind{Type: &clusterv1alpha1.Machine{}},
 		&handler.EnqueueRequestsFromMapFunc{ToRequests: mapFn},
+		predicates.WatchFilter(),
+	)
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to Clusters and reconcile their MachineSets, so that the ones waiting
+	// for the control plane to be initialized create their Machines.
+	return c.Watch(
+		&source.Kind{Type: &clusterv1alpha1.Cluster{}},
+		&handler.EnqueueRequestsFromMapFunc{ToRequests: clusterMapFn},
+		predicates.WatchFilter(),
 	)
 }
 
@@ -112,14 +142,17 @@ type ReconcileMachineSet struct {
 	client.Client
 	scheme   *runtime.Scheme
 	recorder record.EventRecorder
+	tracker  *remote.ClusterCacheTracker
 }
 
 // Reconcile reads that state of the cluster for a MachineSet object and makes changes based on the state read
 // and what is in the MachineSet.Spec
 // Automatically generate RBAC rules to allow the Controller to read and write Deployments
 func (r *ReconcileMachineSet) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	ctx, span := tracing.StartReconcile(context.Background(), controllerName, request)
+	defer span.End()
+
 	// Fetch the MachineSet instance
-	ctx := context.TODO()
 	machineSet := &clusterv1alpha1.MachineSet{}
 	if err := r.Get(ctx, request.NamespacedName, machineSet); err != nil {
 		if apierrors.IsNotFound(err) {
@@ -139,14 +172,15 @@ func (r *ReconcileMachineSet) Reconcile(request reconcile.Request) (reconcile.Re
 
 	result, err := r.reconcile(ctx, machineSet)
 	if err != nil {
-		klog.Errorf("Failed to reconcile MachineSet %q: %v", request.NamespacedName, err)
+		log.Error(err, "Failed to reconcile MachineSet", "machineset", request.Name, "namespace", request.Namespace)
 		r.recorder.Eventf(machineSet, corev1.EventTypeWarning, "ReconcileError", "%v", err)
 	}
 	return result, err
 }
 
 func (r *ReconcileMachineSet) reconcile(ctx context.Context, machineSet *clusterv1alpha1.MachineSet) (reconcile.Result, error) {
-	klog.V(4).Infof("Reconcile machineset %v", machineSet.Name)
+	logger := log.WithValues("machineset", machineSet.Name, "namespace", machineSet.Namespace)
+	logger.V(4).Info("Reconciling MachineSet")
 	allMachines := &clusterv1alpha1.MachineList{}
 
 	if err := r.Client.List(context.Background(), allMachines, client.InNamespace(machineSet.Namespace)); err != nil {
@@ -191,7 +225,7 @@ func (r *ReconcileMachineSet) reconcile(ctx context.Context, machineSet *cluster
 		machineSet.Finalizers = append(machineSet.ObjectMeta.Finalizers, metav1.FinalizerDeleteDependents)
 
 		if err := r.Client.Update(context.Background(), machineSet); err != nil {
-			klog.Infof("Failed to add finalizers to MachineSet %q: %v", machineSet.Name, err)
+			logger.Error(err, "Failed to add finalizers")
 			return reconcile.Result{}, err
 		}
 
@@ -210,18 +244,24 @@ func (r *ReconcileMachineSet) reconcile(ctx context.Context, machineSet *cluster
 		// Attempt to adopt machine if it meets previous conditions and it has no controller references.
 		if metav1.GetControllerOf(machine) == nil {
 			if err := r.adoptOrphan(machineSet, machine); err != nil {
-				klog.Warningf("Failed to adopt Machine %q into MachineSet %q: %v", machine.Name, machineSet.Name, err)
+				logger.Error(err, "Failed to adopt Machine", "machine", machine.Name)
 				r.recorder.Eventf(machineSet, corev1.EventTypeWarning, "FailedAdopt", "Failed to adopt Machine %q: %v", machine.Name, err)
 				continue
 			}
-			klog.Infof("Adopted Machine %q into MachineSet %q", machine.Name, machineSet.Name)
+			logger.Info("Adopted Machine", "machine", machine.Name)
 			r.recorder.Eventf(machineSet, corev1.EventTypeNormal, "SuccessfulAdopt", "Adopted Machine %q", machine.Name)
 		}
 
 		filteredMachines = append(filteredMachines, machine)
 	}
 
-	syncErr := r.syncReplicas(machineSet, filteredMachines)
+	// Delete the Machines failed for too long before syncing, so that they are replaced right away.
+	filteredMachines, failedRequeueAfter, err := r.deleteFailedMachines(ctx, machineSet, filteredMachines)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	syncErr := r.syncReplicas(machineSet, cluster, filteredMachines)
 
 	ms := machineSet.DeepCopy()
 	newStatus := r.calculateStatus(ms, filteredMachines)
@@ -255,16 +295,16 @@ func (r *ReconcileMachineSet) reconcile(ctx context.Context, machineSet *cluster
 		updatedMS.Status.ReadyReplicas == replicas &&
 		updatedMS.Status.AvailableReplicas != replicas {
 
-		return reconcile.Result{RequeueAfter: time.Duration(updatedMS.Spec.MinReadySeconds) * time.Second}, nil
+		return reconcile.Result{RequeueAfter: minRequeueAfter(time.Duration(updatedMS.Spec.MinReadySeconds)*time.Second, failedRequeueAfter)}, nil
 	}
 
-	return reconcile.Result{}, nil
+	return reconcile.Result{RequeueAfter: failedRequeueAfter}, nil
 }
 
 // getCluster reuturns the Cluster associated with the MachineSet, if any.
 func (r *ReconcileMachineSet) getCluster(ms *clusterv1alpha1.MachineSet) (*clusterv1alpha1.Cluster, error) {
 	if ms.Spec.Template.Labels[clusterv1alpha1.MachineClusterLabelName] == "" {
-		klog.Infof("MachineSet %q in namespace %q doesn't specify %q label, assuming nil cluster", ms.Name, ms.Namespace, clusterv1alpha1.MachineClusterLabelName)
+		log.Info("MachineSet doesn't specify the cluster label, assuming nil cluster", "machineset", ms.Name, "namespace", ms.Namespace, "label", clusterv1alpha1.MachineClusterLabelName)
 		return nil, nil
 	}
 
@@ -282,32 +322,44 @@ func (r *ReconcileMachineSet) getCluster(ms *clusterv1alpha1.MachineSet) (*clust
 }
 
 // syncReplicas scales Machine resources up or down.
-func (r *ReconcileMachineSet) syncReplicas(ms *clusterv1alpha1.MachineSet, machines []*clusterv1alpha1.Machine) error {
+func (r *ReconcileMachineSet) syncReplicas(ms *clusterv1alpha1.MachineSet, cluster *clusterv1alpha1.Cluster, machines []*clusterv1alpha1.Machine) error {
 	if ms.Spec.Replicas == nil {
 		return errors.Errorf("the Replicas field in Spec for machineset %v is nil, this should not be allowed", ms.Name)
 	}
 
+	logger := log.WithValues("machineset", ms.Name, "namespace", ms.Namespace)
 	diff := len(machines) - int(*(ms.Spec.Replicas))
+	spread := spreadsAcrossFailureDomains(ms, cluster)
 
 	if diff < 0 {
 		diff *= -1
-		klog.Infof("Too few replicas for %v %s/%s, need %d, creating %d",
-			controllerKind, ms.Namespace, ms.Name, *(ms.Spec.Replicas), diff)
+		if waitsForControlPlane(ms, cluster) {
+			logger.Info("Waiting for the control plane to be initialized before creating Machines", "cluster", cluster.Name,
+				"condition", clusterv1alpha1.ControlPlaneInitializedCondition, "need", *(ms.Spec.Replicas), "missing", diff)
+			r.recorder.Eventf(ms, corev1.EventTypeNormal, "WaitingForControlPlane", "Waiting for the %s condition of cluster %q to be True before creating %d machines",
+				clusterv1alpha1.ControlPlaneInitializedCondition, cluster.Name, diff)
+			return nil
+		}
+		logger.Info("Too few replicas", "need", *(ms.Spec.Replicas), "creating", diff)
 
 		var machineList []*clusterv1alpha1.Machine
 		var errstrings []string
 		for i := 0; i < diff; i++ {
-			klog.Infof("Creating machine %d of %d, ( spec.replicas(%d) > currentMachineCount(%d) )",
-				i+1, diff, *(ms.Spec.Replicas), len(machines))
+			logger.Info(fmt.Sprintf("Creating Machine %d of %d", i+1, diff),
+				"replicas", *(ms.Spec.Replicas), "machineCount", len(machines))
 
 			machine := r.createMachine(ms)
+			if spread {
+				failureDomain := leastUsedFailureDomain(cluster.Status.FailureDomains, append(machines, machineList...))
+				machine.Spec.FailureDomain = &failureDomain
+			}
 			if err := r.Client.Create(context.Background(), machine); err != nil {
-				klog.Errorf("Unable to create Machine %q: %v", machine.Name, err)
+				logger.Error(err, "Unable to create Machine", "machine", machine.Name)
 				r.recorder.Eventf(ms, corev1.EventTypeWarning, "FailedCreate", "Failed to create machine %q: %v", machine.Name, err)
 				errstrings = append(errstrings, err.Error())
 				continue
 			}
-			klog.Infof("Created machine %d of %d with name %q", i+1, diff, machine.Name)
+			logger.Info(fmt.Sprintf("Created Machine %d of %d", i+1, diff), "machine", machine.Name)
 			r.recorder.Eventf(ms, corev1.EventTypeNormal, "SuccessfulCreate", "Created machine %q", machine.Name)
 
 			machineList = append(machineList, machine)
@@ -319,16 +371,20 @@ func (r *ReconcileMachineSet) syncReplicas(ms *clusterv1alpha1.MachineSet, machi
 
 		return r.waitForMachineCreation(machineList)
 	} else if diff > 0 {
-		klog.Infof("Too many replicas for %v %s/%s, need %d, deleting %d",
-			controllerKind, ms.Namespace, ms.Name, *(ms.Spec.Replicas), diff)
+		logger.Info("Too many replicas", "need", *(ms.Spec.Replicas), "deleting", diff)
 
 		deletePriorityFunc, err := getDeletePriorityFunc(ms)
 		if err != nil {
 			return err
 		}
-		klog.Infof("Found %s delete policy", ms.Spec.DeletePolicy)
+		logger.Info("Found delete policy", "deletePolicy", ms.Spec.DeletePolicy)
 		// Choose which Machines to delete.
-		machinesToDelete := getMachinesToDeletePrioritized(machines, diff, deletePriorityFunc)
+		var machinesToDelete []*clusterv1alpha1.Machine
+		if spread {
+			machinesToDelete = getMachinesToDeleteSpread(cluster.Status.FailureDomains, machines, diff, deletePriorityFunc)
+		} else {
+			machinesToDelete = getMachinesToDeletePrioritized(machines, diff, deletePriorityFunc)
+		}
 
 		// TODO: Add cap to limit concurrent delete calls.
 		errCh := make(chan error, diff)
@@ -339,11 +395,11 @@ func (r *ReconcileMachineSet) syncReplicas(ms *clusterv1alpha1.MachineSet, machi
 				defer wg.Done()
 				err := r.Client.Delete(context.Background(), targetMachine)
 				if err != nil {
-					klog.Errorf("Unable to delete Machine %s: %v", targetMachine.Name, err)
+					logger.Error(err, "Unable to delete Machine", "machine", targetMachine.Name)
 					r.recorder.Eventf(ms, corev1.EventTypeWarning, "FailedDelete", "Failed to delete machine %q: %v", targetMachine.Name, err)
 					errCh <- err
 				}
-				klog.Infof("Deleted machine %q", targetMachine.Name)
+				logger.Info("Deleted Machine", "machine", targetMachine.Name)
 				r.recorder.Eventf(ms, corev1.EventTypeNormal, "SuccessfulDelete", "Deleted machine %q", targetMachine.Name)
 			}(machine)
 		}
@@ -389,7 +445,7 @@ func (r *ReconcileMachineSet) createMachine(machineSet *clusterv1alpha1.MachineS
 func shouldExcludeMachine(machineSet *clusterv1alpha1.MachineSet, machine *clusterv1alpha1.Machine) bool {
 	// Ignore inactive machines.
 	if metav1.GetControllerOf(machine) != nil && !metav1.IsControlledBy(machine, machineSet) {
-		klog.V(4).Infof("%s not controlled by %v", machine.Name, machineSet.Name)
+		log.V(4).Info("Machine not controlled by MachineSet", "machine", machine.Name, "machineset", machineSet.Name, "namespace", machineSet.Namespace)
 		return true
 	}
 
@@ -420,7 +476,7 @@ func (r *ReconcileMachineSet) waitForMachineCreation(machineList []*clusterv1alp
 				if apierrors.IsNotFound(err) {
 					return false, nil
 				}
-				klog.Error(err)
+				log.Error(err, "Failed to get Machine", "machine", machine.Name, "namespace", machine.Namespace)
 				return false, err
 			}
 
@@ -428,7 +484,7 @@ func (r *ReconcileMachineSet) waitForMachineCreation(machineList []*clusterv1alp
 		})
 
 		if pollErr != nil {
-			klog.Error(pollErr)
+			log.Error(pollErr, "Failed waiting for Machine to be created", "machine", machine.Name, "namespace", machine.Namespace)
 			return errors.Wrap(pollErr, "failed waiting for machine object to be created")
 		}
 	}
@@ -451,7 +507,7 @@ func (r *ReconcileMachineSet) waitForMachineDeletion(machineList []*clusterv1alp
 		})
 
 		if pollErr != nil {
-			klog.Error(pollErr)
+			log.Error(pollErr, "Failed waiting for Machine to be deleted", "machine", machine.Name, "namespace", machine.Namespace)
 			return errors.Wrap(pollErr, "failed waiting for machine object to be deleted")
 		}
 	}
@@ -467,7 +523,7 @@ func (r *ReconcileMachineSet) MachineToMachineSets(o handler.MapObject) []reconc
 	key := client.ObjectKey{Namespace: o.Meta.GetNamespace(), Name: o.Meta.GetName()}
 	if err := r.Client.Get(context.Background(), key, m); err != nil {
 		if !apierrors.IsNotFound(err) {
-			klog.Errorf("Unable to retrieve Machine %q for possible MachineSet adoption: %v", key, err)
+			log.Error(err, "Unable to retrieve Machine for possible MachineSet adoption", "machine", key.Name, "namespace", key.Namespace)
 		}
 		return nil
 	}
@@ -482,7 +538,7 @@ func (r *ReconcileMachineSet) MachineToMachineSets(o handler.MapObject) []reconc
 
 	mss := r.getMachineSetsForMachine(m)
 	if len(mss) == 0 {
-		klog.V(4).Infof("Found no MachineSet for Machine %q", m.Name)
+		log.V(4).Info("Found no MachineSet for Machine", "machine", m.Name, "namespace", m.Namespace)
 		return nil
 	}
 
@@ -24,6 +24,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	clusterv1alpha1 "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/controller/remote"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -57,9 +58,9 @@ func TestReconcile(t *testing.T) {
 	}
 	c = mgr.GetClient()
 
-	r := newReconciler(mgr)
+	r := newReconciler(mgr, remote.NewClusterCacheTracker(c))
 	recFn, requests := SetupTestReconcile(r)
-	if err := add(mgr, recFn, r.MachineToMachineSets); err != nil {
+	if err := add(mgr, recFn, r.MachineToMachineSets, r.ClusterToMachineSets); err != nil {
 		t.Errorf("error adding controller to manager: %v", err)
 	}
 	defer close(StartTestManager(mgr, t))
@@ -23,10 +23,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/klog"
 	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
 	"sigs.k8s.io/cluster-api/pkg/controller/noderefutil"
-	"sigs.k8s.io/cluster-api/pkg/controller/remote"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -57,15 +55,15 @@ func (c *ReconcileMachineSet) calculateStatus(ms *v1alpha1.MachineSet, filteredM
 		}
 
 		if machine.Status.NodeRef == nil {
-			klog.Warningf("Unable to retrieve Node status for Machine %q in namespace %q: missing NodeRef",
-				machine.Name, machine.Namespace)
+			log.Info("Unable to retrieve Node status for Machine: missing NodeRef",
+				"machine", machine.Name, "namespace", machine.Namespace)
 			continue
 		}
 
 		node, err := c.getMachineNode(cluster, machine)
 		if err != nil {
-			klog.Warningf("Unable to retrieve Node status for Machine %q in namespace %q: %v",
-				machine.Name, machine.Namespace, err)
+			log.Error(err, "Unable to retrieve Node status for Machine",
+				"machine", machine.Name, "namespace", machine.Namespace)
 			continue
 		}
 
@@ -109,12 +107,13 @@ func updateMachineSetStatus(c client.Client, ms *v1alpha1.MachineSet, newStatus
 		if ms.Spec.Replicas != nil {
 			replicas = *ms.Spec.Replicas
 		}
-		klog.V(4).Infof(fmt.Sprintf("Updating status for %v: %s/%s, ", ms.Kind, ms.Namespace, ms.Name) +
-			fmt.Sprintf("replicas %d->%d (need %d), ", ms.Status.Replicas, newStatus.Replicas, replicas) +
-			fmt.Sprintf("fullyLabeledReplicas %d->%d, ", ms.Status.FullyLabeledReplicas, newStatus.FullyLabeledReplicas) +
-			fmt.Sprintf("readyReplicas %d->%d, ", ms.Status.ReadyReplicas, newStatus.ReadyReplicas) +
-			fmt.Sprintf("availableReplicas %d->%d, ", ms.Status.AvailableReplicas, newStatus.AvailableReplicas) +
-			fmt.Sprintf("sequence No: %v->%v", ms.Status.ObservedGeneration, newStatus.ObservedGeneration))
+		log.V(4).Info("Updating status",
+			"machineset", ms.Name, "namespace", ms.Namespace,
+			"replicas", fmt.Sprintf("%d->%d (need %d)", ms.Status.Replicas, newStatus.Replicas, replicas),
+			"fullyLabeledReplicas", fmt.Sprintf("%d->%d", ms.Status.FullyLabeledReplicas, newStatus.FullyLabeledReplicas),
+			"readyReplicas", fmt.Sprintf("%d->%d", ms.Status.ReadyReplicas, newStatus.ReadyReplicas),
+			"availableReplicas", fmt.Sprintf("%d->%d", ms.Status.AvailableReplicas, newStatus.AvailableReplicas),
+			"observedGeneration", fmt.Sprintf("%d->%d", ms.Status.ObservedGeneration, newStatus.ObservedGeneration))
 
 		ms.Status = newStatus
 		updateErr = c.Status().Update(context.Background(), ms)
@@ -145,12 +144,7 @@ func (c *ReconcileMachineSet) getMachineNode(cluster *v1alpha1.Cluster, machine
 	}
 
 	// Otherwise, proceed to get the remote cluster client and get the Node.
-	remoteClient, err := remote.NewClusterClient(c.Client, cluster)
-	if err != nil {
-		return nil, err
-	}
-
-	corev1Remote, err := remoteClient.CoreV1()
+	corev1Remote, err := c.tracker.CoreV1(cluster)
 	if err != nil {
 		return nil, err
 	}
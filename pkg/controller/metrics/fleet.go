@@ -0,0 +1,105 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/util"
+)
+
+var (
+	machinesByVersionDesc = prometheus.NewDesc(
+		"capi_machines_by_version",
+		"Number of Machines by namespace and kubelet version.",
+		[]string{"namespace", "version"}, nil,
+	)
+	machinesByFailureDomainDesc = prometheus.NewDesc(
+		"capi_machines_by_failure_domain",
+		"Number of Machines by namespace and failure domain.",
+		[]string{"namespace", "failure_domain"}, nil,
+	)
+	controlPlaneUpgradePendingDesc = prometheus.NewDesc(
+		"capi_cluster_control_plane_upgrade_pending",
+		"Whether the control plane Machines of a Cluster don't all run the version in their spec yet.",
+		[]string{"namespace", "cluster"}, nil,
+	)
+)
+
+func describeFleet(ch chan<- *prometheus.Desc) {
+	ch <- machinesByVersionDesc
+	ch <- machinesByFailureDomainDesc
+	ch <- controlPlaneUpgradePendingDesc
+}
+
+// collectFleet reports the fleet metrics of the machines.
+func collectFleet(ch chan<- prometheus.Metric, machines []v1alpha1.Machine) {
+	versions := counts{}
+	failureDomains := counts{}
+	pending := map[countKey]bool{}
+	for i := range machines {
+		m := &machines[i]
+		versions.add(m.Namespace, machineVersion(m))
+
+		failureDomain := ""
+		if m.Spec.FailureDomain != nil {
+			failureDomain = *m.Spec.FailureDomain
+		}
+		failureDomains.add(m.Namespace, failureDomain)
+
+		cluster := m.Labels[v1alpha1.MachineClusterLabelName]
+		if !util.IsControlPlaneMachine(m) || cluster == "" {
+			continue
+		}
+		key := countKey{namespace: m.Namespace, value: cluster}
+		pending[key] = pending[key] || upgradePending(m)
+	}
+
+	versions.collect(ch, machinesByVersionDesc)
+	failureDomains.collect(ch, machinesByFailureDomainDesc)
+	for k, isPending := range pending {
+		value := 0.0
+		if isPending {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(controlPlaneUpgradePendingDesc, prometheus.GaugeValue, value, k.namespace, k.value)
+	}
+}
+
+// machineVersion returns the kubelet version reported for the machine, or the one in its spec
+// if none is reported yet.
+func machineVersion(m *v1alpha1.Machine) string {
+	if m.Status.Versions != nil && m.Status.Versions.Kubelet != "" {
+		return normalizeVersion(m.Status.Versions.Kubelet)
+	}
+	return normalizeVersion(m.Spec.Versions.Kubelet)
+}
+
+// upgradePending returns true if the control plane version reported for the machine differs
+// from the one in its spec.
+func upgradePending(m *v1alpha1.Machine) bool {
+	if m.Status.Versions == nil || m.Status.Versions.ControlPlane == "" {
+		return false
+	}
+	return normalizeVersion(m.Status.Versions.ControlPlane) != normalizeVersion(m.Spec.Versions.ControlPlane)
+}
+
+func normalizeVersion(version string) string {
+	return strings.TrimPrefix(version, "v")
+}
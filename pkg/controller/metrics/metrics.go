@@ -0,0 +1,204 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/controller/predicates"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Phases reported for Clusters and for Machines whose actuator doesn't set Status.Phase.
+const (
+	PhasePending     = "Pending"
+	PhaseProvisioned = "Provisioned"
+	PhaseRunning     = "Running"
+	PhaseFailed      = "Failed"
+	PhaseDeleting    = "Deleting"
+)
+
+var (
+	// FleetMetrics enables the metrics breaking down the Machines by version and failure
+	// domain, and reporting the control planes with a pending upgrade.
+	FleetMetrics = false
+
+	log = logf.Log.WithName("metrics")
+
+	clustersDesc = prometheus.NewDesc(
+		"capi_clusters",
+		"Number of Clusters by namespace and phase.",
+		[]string{"namespace", "phase"}, nil,
+	)
+	machinesDesc = prometheus.NewDesc(
+		"capi_machines",
+		"Number of Machines by namespace and phase.",
+		[]string{"namespace", "phase"}, nil,
+	)
+	machineDeploymentReplicasDesc = prometheus.NewDesc(
+		"capi_machinedeployment_replicas",
+		"Desired number of Machines of a MachineDeployment.",
+		[]string{"namespace", "name"}, nil,
+	)
+	machineDeploymentUpdatedReplicasDesc = prometheus.NewDesc(
+		"capi_machinedeployment_updated_replicas",
+		"Number of Machines of a MachineDeployment matching its current template.",
+		[]string{"namespace", "name"}, nil,
+	)
+	machineDeploymentAvailableReplicasDesc = prometheus.NewDesc(
+		"capi_machinedeployment_available_replicas",
+		"Number of available Machines of a MachineDeployment.",
+		[]string{"namespace", "name"}, nil,
+	)
+)
+
+// Add registers a collector reporting the state of the Cluster API objects on the metrics
+// endpoint of the Manager. Reconcile counts, errors and durations of every controller are
+// already reported by controller-runtime.
+func Add(mgr manager.Manager) error {
+	return metrics.Registry.Register(&collector{client: mgr.GetClient(), fleet: FleetMetrics})
+}
+
+// collector reads the Cluster API objects from the cache of the Manager when metrics are scraped.
+type collector struct {
+	client client.Client
+
+	// fleet enables the fleet metrics.
+	fleet bool
+}
+
+var _ prometheus.Collector = &collector{}
+
+// Describe implements prometheus.Collector.
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- clustersDesc
+	ch <- machinesDesc
+	ch <- machineDeploymentReplicasDesc
+	ch <- machineDeploymentUpdatedReplicasDesc
+	ch <- machineDeploymentAvailableReplicasDesc
+	if c.fleet {
+		describeFleet(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+
+	// Only report the objects reconciled by this instance of the controllers.
+	var opts []client.ListOptionFunc
+	if predicates.WatchFilterValue != "" {
+		opts = append(opts, client.MatchingLabels(map[string]string{v1alpha1.WatchFilterLabelName: predicates.WatchFilterValue}))
+	}
+
+	clusters := &v1alpha1.ClusterList{}
+	if err := c.client.List(ctx, clusters, opts...); err != nil {
+		log.Error(err, "Failed to list Clusters")
+	} else {
+		phases := counts{}
+		for i := range clusters.Items {
+			phases.add(clusters.Items[i].Namespace, clusterPhase(&clusters.Items[i]))
+		}
+		phases.collect(ch, clustersDesc)
+	}
+
+	machines := &v1alpha1.MachineList{}
+	if err := c.client.List(ctx, machines, opts...); err != nil {
+		log.Error(err, "Failed to list Machines")
+	} else {
+		phases := counts{}
+		for i := range machines.Items {
+			phases.add(machines.Items[i].Namespace, machinePhase(&machines.Items[i]))
+		}
+		phases.collect(ch, machinesDesc)
+
+		if c.fleet {
+			collectFleet(ch, machines.Items)
+		}
+	}
+
+	deployments := &v1alpha1.MachineDeploymentList{}
+	if err := c.client.List(ctx, deployments, opts...); err != nil {
+		log.Error(err, "Failed to list MachineDeployments")
+	} else {
+		for _, d := range deployments.Items {
+			replicas := d.Status.Replicas
+			if d.Spec.Replicas != nil {
+				replicas = *d.Spec.Replicas
+			}
+			ch <- prometheus.MustNewConstMetric(machineDeploymentReplicasDesc, prometheus.GaugeValue,
+				float64(replicas), d.Namespace, d.Name)
+			ch <- prometheus.MustNewConstMetric(machineDeploymentUpdatedReplicasDesc, prometheus.GaugeValue,
+				float64(d.Status.UpdatedReplicas), d.Namespace, d.Name)
+			ch <- prometheus.MustNewConstMetric(machineDeploymentAvailableReplicasDesc, prometheus.GaugeValue,
+				float64(d.Status.AvailableReplicas), d.Namespace, d.Name)
+		}
+	}
+}
+
+// clusterPhase returns the phase of the cluster derived from its status.
+func clusterPhase(cluster *v1alpha1.Cluster) string {
+	switch {
+	case !cluster.DeletionTimestamp.IsZero():
+		return PhaseDeleting
+	case cluster.Status.ErrorReason != "":
+		return PhaseFailed
+	case len(cluster.Status.APIEndpoints) > 0:
+		return PhaseProvisioned
+	default:
+		return PhasePending
+	}
+}
+
+// machinePhase returns the phase set by the actuator of the machine, or one derived from its status.
+func machinePhase(machine *v1alpha1.Machine) string {
+	switch {
+	case machine.Status.Phase != nil && *machine.Status.Phase != "":
+		return *machine.Status.Phase
+	case !machine.DeletionTimestamp.IsZero():
+		return PhaseDeleting
+	case machine.Status.ErrorReason != nil:
+		return PhaseFailed
+	case machine.Status.NodeRef != nil:
+		return PhaseRunning
+	default:
+		return PhasePending
+	}
+}
+
+type countKey struct {
+	namespace string
+	value     string
+}
+
+// counts counts objects by namespace and the value of another label, like their phase.
+type counts map[countKey]int
+
+func (p counts) add(namespace, value string) {
+	p[countKey{namespace: namespace, value: value}]++
+}
+
+func (p counts) collect(ch chan<- prometheus.Metric, desc *prometheus.Desc) {
+	for k, count := range p {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(count), k.namespace, k.value)
+	}
+}
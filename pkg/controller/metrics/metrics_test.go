@@ -0,0 +1,195 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCollect(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+
+	now := metav1.Now()
+	running := "Running"
+	errorReason := common.InvalidConfigurationMachineError
+	replicas := int32(3)
+
+	c := &collector{client: fake.NewFakeClient(
+		&v1alpha1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "default"},
+		},
+		&v1alpha1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "provisioned", Namespace: "default"},
+			Status: v1alpha1.ClusterStatus{
+				APIEndpoints: []v1alpha1.APIEndpoint{{Host: "10.0.0.1", Port: 6443}},
+			},
+		},
+		&v1alpha1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "failed", Namespace: "default"},
+			Status: v1alpha1.ClusterStatus{
+				ErrorReason: common.InvalidConfigurationClusterError,
+			},
+		},
+		&v1alpha1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: "phase", Namespace: "default"},
+			Status:     v1alpha1.MachineStatus{Phase: &running},
+		},
+		&v1alpha1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: "noderef", Namespace: "default"},
+			Status:     v1alpha1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: "node"}},
+		},
+		&v1alpha1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: "failed", Namespace: "default"},
+			Status:     v1alpha1.MachineStatus{ErrorReason: &errorReason},
+		},
+		&v1alpha1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: "deleting", Namespace: "other", DeletionTimestamp: &now},
+		},
+		&v1alpha1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "md", Namespace: "default"},
+			Spec:       v1alpha1.MachineDeploymentSpec{Replicas: &replicas},
+			Status:     v1alpha1.MachineDeploymentStatus{UpdatedReplicas: 2, AvailableReplicas: 1},
+		},
+	)}
+
+	got := collect(t, c)
+
+	expected := map[*prometheus.Desc]map[string]float64{
+		clustersDesc: {
+			",namespace=default,phase=" + PhasePending:     1,
+			",namespace=default,phase=" + PhaseProvisioned: 1,
+			",namespace=default,phase=" + PhaseFailed:      1,
+		},
+		machinesDesc: {
+			",namespace=default,phase=" + PhaseRunning: 2,
+			",namespace=default,phase=" + PhaseFailed:  1,
+			",namespace=other,phase=" + PhaseDeleting:  1,
+		},
+		machineDeploymentReplicasDesc:          {",name=md,namespace=default": 3},
+		machineDeploymentUpdatedReplicasDesc:   {",name=md,namespace=default": 2},
+		machineDeploymentAvailableReplicasDesc: {",name=md,namespace=default": 1},
+	}
+
+	checkMetrics(t, expected, got)
+}
+
+func TestCollectFleet(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+	zoneA := "zone-a"
+
+	machine := func(name, namespace, specVersion, statusVersion string, controlPlane bool) *v1alpha1.Machine {
+		m := &v1alpha1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    map[string]string{v1alpha1.MachineClusterLabelName: "foo"},
+			},
+		}
+		m.Spec.Versions.Kubelet = specVersion
+		if statusVersion != "" {
+			m.Status.Versions = &v1alpha1.MachineVersionInfo{Kubelet: statusVersion}
+		}
+		if controlPlane {
+			m.Spec.Versions.ControlPlane = specVersion
+			if statusVersion != "" {
+				m.Status.Versions.ControlPlane = statusVersion
+			}
+		}
+		return m
+	}
+	upgrading := machine("cp-0", "default", "1.16.2", "v1.15.3", true)
+	upgrading.Spec.FailureDomain = &zoneA
+
+	c := &collector{fleet: true, client: fake.NewFakeClient(
+		upgrading,
+		machine("cp-1", "default", "1.16.2", "1.16.2", true),
+		machine("worker", "default", "1.15.3", "", false),
+		machine("cp-0", "other", "1.16.2", "1.16.2", true),
+	)}
+
+	got := collect(t, c)
+
+	expected := map[*prometheus.Desc]map[string]float64{
+		machinesDesc: {
+			",namespace=default,phase=" + PhasePending: 3,
+			",namespace=other,phase=" + PhasePending:   1,
+		},
+		machinesByVersionDesc: {
+			",namespace=default,version=1.15.3": 2,
+			",namespace=default,version=1.16.2": 1,
+			",namespace=other,version=1.16.2":   1,
+		},
+		machinesByFailureDomainDesc: {
+			",failure_domain=zone-a,namespace=default": 1,
+			",failure_domain=,namespace=default":       2,
+			",failure_domain=,namespace=other":         1,
+		},
+		controlPlaneUpgradePendingDesc: {
+			",cluster=foo,namespace=default": 1,
+			",cluster=foo,namespace=other":   0,
+		},
+	}
+	checkMetrics(t, expected, got)
+}
+
+// collect returns the value of the metrics reported by the collector, by description and labels.
+func collect(t *testing.T, c *collector) map[string]float64 {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		c.Collect(ch)
+		close(ch)
+	}()
+
+	got := map[string]float64{}
+	for m := range ch {
+		metric := &dto.Metric{}
+		if err := m.Write(metric); err != nil {
+			t.Fatalf("failed to write metric: %v", err)
+		}
+		key := m.Desc().String()
+		for _, l := range metric.Label {
+			key += "," + l.GetName() + "=" + l.GetValue()
+		}
+		got[key] = metric.GetGauge().GetValue()
+	}
+	return got
+}
+
+// checkMetrics checks the collected metrics are exactly the expected ones.
+func checkMetrics(t *testing.T, expected map[*prometheus.Desc]map[string]float64, got map[string]float64) {
+	count := 0
+	for desc, values := range expected {
+		for labels, value := range values {
+			count++
+			if actual, ok := got[desc.String()+labels]; !ok || actual != value {
+				t.Errorf("expected %v{%s} = %v, got %v (found: %v)", desc, labels, value, actual, ok)
+			}
+		}
+	}
+	if len(got) != count {
+		t.Errorf("expected %d metrics, got %d: %v", count, len(got), got)
+	}
+}
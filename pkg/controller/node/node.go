@@ -22,7 +22,6 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
-	"k8s.io/klog"
 	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
 	"sigs.k8s.io/cluster-api/pkg/controller/noderefutil"
 	"sigs.k8s.io/cluster-api/pkg/util"
@@ -59,7 +58,7 @@ func (c *ReconcileNode) link(node *corev1.Node) error {
 
 	namespace, mach, err := cache.SplitMetaNamespaceKey(val)
 	if err != nil {
-		klog.Errorf("Machine annotation format is incorrect %v: %v\n", val, err)
+		log.Error(err, "Machine annotation format is incorrect", "node", node.Name, "annotation", val)
 		return err
 	}
 	namespace = util.GetNamespaceOrDefault(namespace)
@@ -67,7 +66,7 @@ func (c *ReconcileNode) link(node *corev1.Node) error {
 
 	machine := &v1alpha1.Machine{}
 	if err = c.Client.Get(context.Background(), key, machine); err != nil {
-		klog.Errorf("Error getting machine %v: %v\n", mach, err)
+		log.Error(err, "Failed to get Machine", "machine", mach, "namespace", namespace)
 		return err
 	}
 
@@ -75,10 +74,10 @@ func (c *ReconcileNode) link(node *corev1.Node) error {
 	machine.Status.LastUpdated = &t
 	machine.Status.NodeRef = objectRef(node)
 	if err = c.Client.Status().Update(context.Background(), machine); err != nil {
-		klog.Errorf("Error updating machine to link to node: %v\n", err)
+		log.Error(err, "Failed to link Machine to Node", "machine", machine.Name, "namespace", machine.Namespace, "node", node.Name)
 	} else {
-		klog.Infof("Successfully linked machine %s to node %s\n",
-			machine.ObjectMeta.Name, node.ObjectMeta.Name)
+		log.Info("Successfully linked Machine to Node",
+			"machine", machine.Name, "namespace", machine.Namespace, "node", node.Name)
 		c.linkedNodes[node.ObjectMeta.Name] = true
 		c.cachedReadiness[node.ObjectMeta.Name] = nodeReady
 	}
@@ -93,7 +92,7 @@ func (c *ReconcileNode) unlink(node *corev1.Node) error {
 
 	namespace, mach, err := cache.SplitMetaNamespaceKey(val)
 	if err != nil {
-		klog.Errorf("Machine annotation format is incorrect %v: %v\n", val, err)
+		log.Error(err, "Machine annotation format is incorrect", "node", node.Name, "annotation", val)
 		return err
 	}
 	namespace = util.GetNamespaceOrDefault(namespace)
@@ -101,7 +100,7 @@ func (c *ReconcileNode) unlink(node *corev1.Node) error {
 
 	machine := &v1alpha1.Machine{}
 	if err = c.Client.Get(context.Background(), key, machine); err != nil {
-		klog.Errorf("Error getting machine %v: %v\n", mach, err)
+		log.Error(err, "Failed to get Machine", "machine", mach, "namespace", namespace)
 		return err
 	}
 
@@ -112,8 +111,8 @@ func (c *ReconcileNode) unlink(node *corev1.Node) error {
 
 	// This machine was linked to a different node, don't unlink them
 	if machine.Status.NodeRef.Name != node.ObjectMeta.Name {
-		klog.Warningf("Node (%v) is tring to unlink machine (%v) which is linked with node (%v).",
-			node.ObjectMeta.Name, machine.ObjectMeta.Name, machine.Status.NodeRef.Name)
+		log.Info("Node is trying to unlink a Machine which is linked with another Node",
+			"node", node.Name, "machine", machine.Name, "namespace", machine.Namespace, "linkedNode", machine.Status.NodeRef.Name)
 		return nil
 	}
 
@@ -121,11 +120,11 @@ func (c *ReconcileNode) unlink(node *corev1.Node) error {
 	machine.Status.LastUpdated = &t
 	machine.Status.NodeRef = nil
 	if err = c.Client.Status().Update(context.Background(), machine); err != nil {
-		klog.Errorf("Error updating machine %s to unlink node %s: %v\n",
-			machine.ObjectMeta.Name, node.ObjectMeta.Name, err)
+		log.Error(err, "Failed to unlink Node from Machine",
+			"machine", machine.Name, "namespace", machine.Namespace, "node", node.Name)
 	} else {
-		klog.Infof("Successfully unlinked node %s from machine %s\n",
-			node.ObjectMeta.Name, machine.ObjectMeta.Name)
+		log.Info("Successfully unlinked Node from Machine",
+			"machine", machine.Name, "namespace", machine.Namespace, "node", node.Name)
 		delete(c.cachedReadiness, node.ObjectMeta.Name)
 		delete(c.linkedNodes, node.ObjectMeta.Name)
 	}
@@ -0,0 +1,111 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderef
+
+import (
+	"context"
+
+	apicorev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/controller/noderefutil"
+	"sigs.k8s.io/cluster-api/pkg/controller/remote"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// providerIDIndex is the name of the index of Machines by the ID of their ProviderID.
+const providerIDIndex = "spec.providerID"
+
+// indexMachineByProviderID is a client.IndexerFunc indexing Machines by the ID of their ProviderID.
+func indexMachineByProviderID(o runtime.Object) []string {
+	machine, ok := o.(*v1alpha1.Machine)
+	if !ok || machine.Spec.ProviderID == nil {
+		return nil
+	}
+
+	providerID, err := noderefutil.NewProviderID(*machine.Spec.ProviderID)
+	if err != nil {
+		return nil
+	}
+	return []string{providerID.ID()}
+}
+
+// nodeInformers watches the Nodes of the workload clusters through the shared
+// Node informers of the ClusterCacheTracker. Machines are enqueued through events
+// as soon as a Node with the same ProviderID registers.
+type nodeInformers struct {
+	client  client.Client
+	tracker *remote.ClusterCacheTracker
+	events  chan event.GenericEvent
+}
+
+func newNodeInformers(c client.Client, tracker *remote.ClusterCacheTracker) *nodeInformers {
+	return &nodeInformers{
+		client:  c,
+		tracker: tracker,
+		events:  make(chan event.GenericEvent),
+	}
+}
+
+// get returns the Nodes of the cluster, indexed by remote.NodeProviderIDIndex.
+func (n *nodeInformers) get(cluster *v1alpha1.Cluster) (cache.Indexer, error) {
+	if err := n.tracker.WatchNodes(cluster, controllerName, cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(o interface{}) { n.enqueueMachines(cluster.Namespace, o) },
+		UpdateFunc: func(_, o interface{}) { n.enqueueMachines(cluster.Namespace, o) },
+	}); err != nil {
+		return nil, err
+	}
+	return n.tracker.Nodes(cluster)
+}
+
+// removeCluster is a handler.Funcs DeleteFunc stopping the Node informer of a deleted Cluster
+// right away, rather than on the next health check of the ClusterCacheTracker.
+func (n *nodeInformers) removeCluster(e event.DeleteEvent, _ workqueue.RateLimitingInterface) {
+	n.tracker.Remove(types.NamespacedName{Namespace: e.Meta.GetNamespace(), Name: e.Meta.GetName()})
+}
+
+// enqueueMachines sends an event for every Machine in namespace whose ProviderID matches the one of the Node.
+func (n *nodeInformers) enqueueMachines(namespace string, o interface{}) {
+	node, ok := o.(*apicorev1.Node)
+	if !ok {
+		return
+	}
+
+	providerID, err := noderefutil.NewProviderID(node.Spec.ProviderID)
+	if err != nil {
+		return
+	}
+
+	machines := &v1alpha1.MachineList{}
+	if err := n.client.List(context.Background(), machines, client.InNamespace(namespace),
+		client.MatchingField(providerIDIndex, providerID.ID())); err != nil {
+		log.Error(err, "Failed to list Machines matching Node", "node", node.Name, "namespace", namespace)
+		return
+	}
+
+	for i := range machines.Items {
+		machine := &machines.Items[i]
+		if machine.Status.NodeRef != nil {
+			continue
+		}
+		n.events <- event.GenericEvent{Meta: machine, Object: machine}
+	}
+}
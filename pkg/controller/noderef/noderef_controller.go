@@ -23,17 +23,19 @@ import (
 	"github.com/pkg/errors"
 	apicorev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
-	"k8s.io/klog"
 	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
 	"sigs.k8s.io/cluster-api/pkg/controller/noderefutil"
+	"sigs.k8s.io/cluster-api/pkg/controller/predicates"
 	"sigs.k8s.io/cluster-api/pkg/controller/remote"
+	"sigs.k8s.io/cluster-api/pkg/tracing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
@@ -44,33 +46,68 @@ const controllerName = "noderef_controller"
 
 var (
 	ErrNodeNotFound = errors.New("cannot find node with matching ProviderID")
+
+	// MaxConcurrentReconciles is the number of Machines whose NodeRef is reconciled in parallel.
+	MaxConcurrentReconciles = 1
+
+	// NodeNotFoundRequeueAfter is how long to wait before looking for the Node of a Machine again.
+	// Machines are enqueued as soon as their Node registers, this is a fallback for when the
+	// connection to the workload cluster, and with it the Node event handlers, was dropped.
+	NodeNotFoundRequeueAfter = 5 * time.Minute
+
+	log = logf.Log.WithName("noderef-controller")
 )
 
 // Add creates a new NodeRef Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr))
+	tracker, err := remote.ClusterCacheTrackerFor(mgr)
+	if err != nil {
+		return err
+	}
+	return add(mgr, newReconciler(mgr, tracker))
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+func newReconciler(mgr manager.Manager, tracker *remote.ClusterCacheTracker) reconcile.Reconciler {
 	return &ReconcileNodeRef{
-		Client:   mgr.GetClient(),
-		scheme:   mgr.GetScheme(),
-		recorder: mgr.GetEventRecorderFor(controllerName),
+		Client:        mgr.GetClient(),
+		scheme:        mgr.GetScheme(),
+		recorder:      mgr.GetEventRecorderFor(controllerName),
+		tracker:       tracker,
+		nodeInformers: newNodeInformers(mgr.GetClient(), tracker),
 	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	// Create a new controller
-	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	c, err := controller.New(controllerName, mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: MaxConcurrentReconciles,
+	})
 	if err != nil {
 		return err
 	}
 
+	// Index Machines by ProviderID, to find the Machines of the Nodes registering in workload clusters.
+	if err := mgr.GetFieldIndexer().IndexField(&v1alpha1.Machine{}, providerIDIndex, indexMachineByProviderID); err != nil {
+		return err
+	}
+
 	// Watch for changes to Machines.
-	return c.Watch(&source.Kind{Type: &v1alpha1.Machine{}}, &handler.EnqueueRequestForObject{})
+	if err := c.Watch(&source.Kind{Type: &v1alpha1.Machine{}}, &handler.EnqueueRequestForObject{}, predicates.WatchFilter()); err != nil {
+		return err
+	}
+
+	// Watch for Nodes registering in workload clusters, and stop watching them once their Cluster is deleted.
+	if nr, ok := r.(*ReconcileNodeRef); ok {
+		if err := c.Watch(&source.Channel{Source: nr.nodeInformers.events}, &handler.EnqueueRequestForObject{}, predicates.WatchFilter()); err != nil {
+			return err
+		}
+		return c.Watch(&source.Kind{Type: &v1alpha1.Cluster{}}, &handler.Funcs{DeleteFunc: nr.nodeInformers.removeCluster}, predicates.WatchFilter())
+	}
+	return nil
 }
 
 var _ reconcile.Reconciler = &ReconcileNodeRef{}
@@ -78,21 +115,25 @@ var _ reconcile.Reconciler = &ReconcileNodeRef{}
 // ReconcileNodeRef reconciles a Machine object to assign a NodeRef.
 type ReconcileNodeRef struct {
 	client.Client
-	scheme   *runtime.Scheme
-	recorder record.EventRecorder
+	scheme        *runtime.Scheme
+	recorder      record.EventRecorder
+	tracker       *remote.ClusterCacheTracker
+	nodeInformers *nodeInformers
 }
 
 // Reconcile responds to Machine events to assign a NodeRef.
 func (r *ReconcileNodeRef) Reconcile(request reconcile.Request) (reconcile.Result, error) {
-	klog.Infof("Reconcile request for Machine %q in namespace %q", request.Name, request.Namespace)
-	ctx := context.Background()
+	logger := log.WithValues("machine", request.Name, "namespace", request.Namespace)
+	logger.Info("Reconciling Machine")
+	ctx, span := tracing.StartReconcile(context.Background(), controllerName, request)
+	defer span.End()
 
 	// Fetch the Machine instance.
 	machine := &v1alpha1.Machine{}
 	err := r.Get(ctx, request.NamespacedName, machine)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			klog.V(2).Infof("Machine %q in namespace %q is not found, won't reconcile", machine.Name, machine.Namespace)
+			logger.V(2).Info("Machine is not found, won't reconcile")
 			return reconcile.Result{}, nil
 		}
 		return reconcile.Result{}, err
@@ -100,20 +141,19 @@ func (r *ReconcileNodeRef) Reconcile(request reconcile.Request) (reconcile.Resul
 
 	// Check that the Machine hasn't been deleted or in the process.
 	if !machine.DeletionTimestamp.IsZero() {
-		klog.V(2).Infof("Machine %q in namespace %q has been deleted, won't reconcile", machine.Name, machine.Namespace)
+		logger.V(2).Info("Machine has been deleted, won't reconcile")
 		return reconcile.Result{}, nil
 	}
 
 	// Check that the Machine doesn't already have a NodeRef.
 	if machine.Status.NodeRef != nil {
-		klog.V(2).Infof("Machine %q in namespace %q already has a NodeRef, won't reconcile", machine.Name, machine.Namespace)
+		logger.V(2).Info("Machine already has a NodeRef, won't reconcile")
 		return reconcile.Result{}, nil
 	}
 
 	// Check that the Machine has a cluster label.
 	if machine.Labels[v1alpha1.MachineClusterLabelName] == "" {
-		klog.V(2).Infof("Machine %q in namespace %q doesn't specify %q label, won't reconcile", machine.Name, machine.Namespace,
-			v1alpha1.MachineClusterLabelName)
+		logger.V(2).Info("Machine doesn't specify the cluster label, won't reconcile", "label", v1alpha1.MachineClusterLabelName)
 		return reconcile.Result{}, nil
 	}
 
@@ -121,7 +161,8 @@ func (r *ReconcileNodeRef) Reconcile(request reconcile.Request) (reconcile.Resul
 	cluster, err := r.getCluster(ctx, machine)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			klog.Warningf("Cannot find a Cluster for Machine %q in namespace %q, won't reconcile", machine.Name, machine.Namespace)
+			r.tracker.Remove(types.NamespacedName{Namespace: machine.Namespace, Name: machine.Labels[v1alpha1.MachineClusterLabelName]})
+			logger.Info("Cannot find a Cluster for Machine, won't reconcile", "cluster", machine.Labels[v1alpha1.MachineClusterLabelName])
 			return reconcile.Result{}, err
 		}
 		return reconcile.Result{}, err
@@ -129,18 +170,21 @@ func (r *ReconcileNodeRef) Reconcile(request reconcile.Request) (reconcile.Resul
 
 	// Check that the Machine has a valid ProviderID.
 	if machine.Spec.ProviderID == nil || *machine.Spec.ProviderID == "" {
-		klog.Warningf("Machine %q in namespace %q doesn't have a valid ProviderID, retrying later", machine.Name, machine.Namespace)
+		logger.Info("Machine doesn't have a valid ProviderID, retrying later")
 		return reconcile.Result{RequeueAfter: 30 * time.Second}, nil
 	}
 
 	result, err := r.reconcile(ctx, cluster, machine)
 	if err != nil {
-		klog.Errorf("Failed to assign NodeRef to Machine %q: %v", request.NamespacedName, err)
+		logger.Error(err, "Failed to assign NodeRef")
 		r.recorder.Event(machine, apicorev1.EventTypeWarning, "FailedSetNodeRef", err.Error())
 		return result, err
 	}
+	if machine.Status.NodeRef == nil {
+		return result, nil
+	}
 
-	klog.Infof("Set Machine's (%q in namespace %q) NodeRef to %q", machine.Name, machine.Namespace, machine.Status.NodeRef.Name)
+	logger.Info("Set NodeRef", "node", machine.Status.NodeRef.Name)
 	r.recorder.Event(machine, apicorev1.EventTypeNormal, "SuccessfulSetNodeRef", machine.Status.NodeRef.Name)
 	return result, nil
 }
@@ -151,22 +195,18 @@ func (r *ReconcileNodeRef) reconcile(ctx context.Context, cluster *v1alpha1.Clus
 		return reconcile.Result{}, err
 	}
 
-	clusterClient, err := remote.NewClusterClient(r.Client, cluster)
-	if err != nil {
-		return reconcile.Result{}, err
-	}
-
-	corev1Client, err := clusterClient.CoreV1()
+	nodes, err := r.nodeInformers.get(cluster)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
 	// Get the Node reference.
-	nodeRef, err := r.getNodeReference(corev1Client, providerID)
+	nodeRef, err := r.getNodeReference(nodes, providerID)
 	if err != nil {
 		if err == ErrNodeNotFound {
-			klog.Warningf("Cannot find a matching Node for Machine %q in namespace %q, retrying later", machine.Name, machine.Namespace)
-			return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
+			// The Machine is reconciled again by the Node informer once a matching Node registers.
+			log.Info("Cannot find a matching Node for Machine, waiting for it to register", "machine", machine.Name, "namespace", machine.Namespace)
+			return reconcile.Result{RequeueAfter: NodeNotFoundRequeueAfter}, nil
 		}
 		return reconcile.Result{}, err
 	}
@@ -194,35 +234,31 @@ func (r *ReconcileNodeRef) getCluster(ctx context.Context, machine *v1alpha1.Mac
 	return cluster, nil
 }
 
-func (r *ReconcileNodeRef) getNodeReference(client corev1.NodesGetter, providerID *noderefutil.ProviderID) (*apicorev1.ObjectReference, error) {
-	listOpt := metav1.ListOptions{}
+func (r *ReconcileNodeRef) getNodeReference(nodes cache.Indexer, providerID *noderefutil.ProviderID) (*apicorev1.ObjectReference, error) {
+	objs, err := nodes.ByIndex(remote.NodeProviderIDIndex, providerID.ID())
+	if err != nil {
+		return nil, err
+	}
 
-	for {
-		nodeList, err := client.Nodes().List(listOpt)
-		if err != nil {
-			return nil, err
+	for _, o := range objs {
+		node, ok := o.(*apicorev1.Node)
+		if !ok {
+			continue
 		}
 
-		for _, node := range nodeList.Items {
-			nodeProviderID, err := noderefutil.NewProviderID(node.Spec.ProviderID)
-			if err != nil {
-				klog.V(3).Infof("Failed to parse ProviderID for Node %q: %v", node.Name, err)
-				continue
-			}
-
-			if providerID.Equals(nodeProviderID) {
-				return &apicorev1.ObjectReference{
-					Kind:       node.Kind,
-					APIVersion: node.APIVersion,
-					Name:       node.Name,
-					UID:        node.UID,
-				}, nil
-			}
+		nodeProviderID, err := noderefutil.NewProviderID(node.Spec.ProviderID)
+		if err != nil {
+			log.V(3).Info("Failed to parse ProviderID for Node", "node", node.Name, "error", err.Error())
+			continue
 		}
 
-		listOpt.Continue = nodeList.Continue
-		if listOpt.Continue == "" {
-			break
+		if providerID.Equals(nodeProviderID) {
+			return &apicorev1.ObjectReference{
+				Kind:       node.Kind,
+				APIVersion: node.APIVersion,
+				Name:       node.Name,
+				UID:        node.UID,
+			}, nil
 		}
 	}
 
@@ -26,13 +26,13 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	fakeclient "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
 	"sigs.k8s.io/cluster-api/pkg/controller/noderefutil"
+	"sigs.k8s.io/cluster-api/pkg/controller/remote"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -60,7 +60,7 @@ func TestReconcile(t *testing.T) {
 	g.Expect(err).NotTo(gomega.HaveOccurred())
 	c = mgr.GetClient()
 
-	recFn, requests := SetupTestReconcile(newReconciler(mgr))
+	recFn, requests := SetupTestReconcile(newReconciler(mgr, remote.NewClusterCacheTracker(c)))
 	g.Expect(add(mgr, recFn)).NotTo(gomega.HaveOccurred())
 
 	stopMgr, mgrStopped := StartTestManager(mgr, g)
@@ -91,7 +91,7 @@ func TestGetNodeReference(t *testing.T) {
 		recorder: record.NewFakeRecorder(32),
 	}
 
-	nodeList := []runtime.Object{
+	nodeList := []interface{}{
 		&corev1.Node{
 			ObjectMeta: metav1.ObjectMeta{
 				Name: "node-1",
@@ -118,7 +118,12 @@ func TestGetNodeReference(t *testing.T) {
 		},
 	}
 
-	coreV1Client := fakeclient.NewSimpleClientset(nodeList...).CoreV1()
+	nodes := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{remote.NodeProviderIDIndex: remote.IndexNodeByProviderID})
+	for _, node := range nodeList {
+		if err := nodes.Add(node); err != nil {
+			t.Fatalf("Expected no error adding node, got %v", err)
+		}
+	}
 
 	testCases := []struct {
 		name       string
@@ -156,7 +161,7 @@ func TestGetNodeReference(t *testing.T) {
 				t.Fatalf("Expected no error parsing provider id %q, got %v", test.providerID, err)
 			}
 
-			reference, err := r.getNodeReference(coreV1Client, providerID)
+			reference, err := r.getNodeReference(nodes, providerID)
 			if err != nil {
 				if (test.err != nil && !strings.Contains(err.Error(), test.err.Error())) || test.err == nil {
 					t.Fatalf("Expected error %v, got %v", test.err, err)
@@ -178,3 +183,37 @@ func TestGetNodeReference(t *testing.T) {
 
 	}
 }
+
+func TestIndexMachineByProviderID(t *testing.T) {
+	providerID := "aws:///us-east-1a/id-node-1"
+	invalidProviderID := "id-node-1"
+
+	testCases := []struct {
+		name     string
+		machine  *v1alpha1.Machine
+		expected []string
+	}{
+		{
+			name:    "no provider id",
+			machine: &v1alpha1.Machine{},
+		},
+		{
+			name:    "invalid provider id",
+			machine: &v1alpha1.Machine{Spec: v1alpha1.MachineSpec{ProviderID: &invalidProviderID}},
+		},
+		{
+			name:     "valid provider id",
+			machine:  &v1alpha1.Machine{Spec: v1alpha1.MachineSpec{ProviderID: &providerID}},
+			expected: []string{"id-node-1"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			got := indexMachineByProviderID(test.machine)
+			if len(got) != len(test.expected) || (len(got) == 1 && got[0] != test.expected[0]) {
+				t.Fatalf("Expected index values %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
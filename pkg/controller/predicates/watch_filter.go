@@ -0,0 +1,59 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package predicates contains the event filters shared by the controllers.
+package predicates
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// WatchFilterValue is the value of the v1alpha1.WatchFilterLabelName label of the objects
+// reconciled by the controllers. All objects are reconciled when it is empty.
+var WatchFilterValue string
+
+// WatchFilter returns a predicate accepting the events of objects whose v1alpha1.WatchFilterLabelName
+// label matches WatchFilterValue, or the events of all objects when WatchFilterValue is empty.
+func WatchFilter() predicate.Funcs {
+	value := WatchFilterValue
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return hasWatchFilterLabel(e.Meta, value)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return hasWatchFilterLabel(e.MetaNew, value)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return hasWatchFilterLabel(e.Meta, value)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return hasWatchFilterLabel(e.Meta, value)
+		},
+	}
+}
+
+func hasWatchFilterLabel(o metav1.Object, value string) bool {
+	if value == "" {
+		return true
+	}
+	if o == nil {
+		return false
+	}
+	return o.GetLabels()[v1alpha1.WatchFilterLabelName] == value
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicates
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestWatchFilter(t *testing.T) {
+	testcases := []struct {
+		name     string
+		filter   string
+		labels   map[string]string
+		expected bool
+	}{
+		{
+			name:     "no filter, no label",
+			expected: true,
+		},
+		{
+			name:     "no filter, labeled",
+			labels:   map[string]string{v1alpha1.WatchFilterLabelName: "tenant-a"},
+			expected: true,
+		},
+		{
+			name:     "filter, no label",
+			filter:   "tenant-a",
+			expected: false,
+		},
+		{
+			name:     "filter, matching label",
+			filter:   "tenant-a",
+			labels:   map[string]string{v1alpha1.WatchFilterLabelName: "tenant-a"},
+			expected: true,
+		},
+		{
+			name:     "filter, other label",
+			filter:   "tenant-a",
+			labels:   map[string]string{v1alpha1.WatchFilterLabelName: "tenant-b"},
+			expected: false,
+		},
+	}
+
+	defer func(value string) { WatchFilterValue = value }(WatchFilterValue)
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			WatchFilterValue = tc.filter
+			p := WatchFilter()
+
+			machine := &v1alpha1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "machine", Labels: tc.labels}}
+			if actual := p.Create(event.CreateEvent{Meta: machine, Object: machine}); actual != tc.expected {
+				t.Errorf("Create: expected %v, got %v", tc.expected, actual)
+			}
+			if actual := p.Update(event.UpdateEvent{MetaOld: machine, ObjectOld: machine, MetaNew: machine, ObjectNew: machine}); actual != tc.expected {
+				t.Errorf("Update: expected %v, got %v", tc.expected, actual)
+			}
+			if actual := p.Delete(event.DeleteEvent{Meta: machine, Object: machine}); actual != tc.expected {
+				t.Errorf("Delete: expected %v, got %v", tc.expected, actual)
+			}
+			if actual := p.Generic(event.GenericEvent{Meta: machine, Object: machine}); actual != tc.expected {
+				t.Errorf("Generic: expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/tracing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -40,6 +41,15 @@ type clusterClient struct {
 
 // NewClusterClient creates a new ClusterClient.
 func NewClusterClient(c client.Client, cluster *v1alpha1.Cluster) (ClusterClient, error) {
+	clusterClient, err := newClusterClient(c, cluster)
+	if err != nil {
+		clusterClientErrors.WithLabelValues(cluster.Namespace, cluster.Name).Inc()
+		return nil, err
+	}
+	return clusterClient, nil
+}
+
+func newClusterClient(c client.Client, cluster *v1alpha1.Cluster) (ClusterClient, error) {
 	secret, err := GetKubeConfigSecret(c, cluster.Name, cluster.Namespace)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to retrieve kubeconfig secret for Cluster %q in namespace %q",
@@ -58,6 +68,9 @@ func NewClusterClient(c client.Client, cluster *v1alpha1.Cluster) (ClusterClient
 			cluster.Name, cluster.Namespace)
 	}
 
+	// Trace the requests to the workload cluster.
+	tracing.WrapTransport(restConfig)
+
 	return &clusterClient{
 		restConfig: restConfig,
 		cluster:    cluster,
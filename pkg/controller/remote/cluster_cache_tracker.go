@@ -0,0 +1,356 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	apicorev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/controller/noderefutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// NodeProviderIDIndex is the name of the index of the Nodes returned by
+// ClusterCacheTracker.Nodes, by the ID of their ProviderID.
+const NodeProviderIDIndex = "spec.providerID"
+
+var (
+	// HealthCheckInterval is how often the connections to the workload clusters are checked.
+	HealthCheckInterval = 10 * time.Second
+
+	// HealthCheckTimeout is how long a workload cluster has to answer a health check.
+	HealthCheckTimeout = 5 * time.Second
+
+	// HealthCheckFailureThreshold is the number of consecutive failed health checks
+	// after which the connection to a workload cluster is torn down.
+	HealthCheckFailureThreshold = 3
+
+	// nodeInformerSyncTimeout is how long to wait for the Node informer of a
+	// workload cluster to list its Nodes before giving up.
+	nodeInformerSyncTimeout = 30 * time.Second
+
+	log = logf.Log.WithName("remote")
+
+	trackersLock sync.Mutex
+	trackers     = map[manager.Manager]*ClusterCacheTracker{}
+)
+
+// ClusterCacheTracker keeps a single set of clients and informers per workload cluster,
+// shared by all the controllers of a Manager. Connections failing their health checks,
+// or whose Cluster is deleted, are torn down, and rebuilt from the kubeconfig secret of
+// the cluster the next time they are used.
+type ClusterCacheTracker struct {
+	client client.Client
+
+	lock     sync.Mutex
+	clusters map[types.NamespacedName]*trackedCluster
+}
+
+// trackedCluster holds the connection to a workload cluster.
+type trackedCluster struct {
+	restConfig *restclient.Config
+	coreV1     corev1.CoreV1Interface
+	health     restclient.Interface
+
+	// client and nodes are created the first time they are requested. nodes may not
+	// be synced yet, the callers of getNodes wait for it without holding t.lock.
+	client   client.Client
+	nodes    cache.SharedIndexInformer
+	handlers map[string]bool
+
+	failures int
+	stop     chan struct{}
+}
+
+// ClusterCacheTrackerFor returns the ClusterCacheTracker of the Manager, creating it
+// and adding it to the Manager the first time it is requested.
+func ClusterCacheTrackerFor(mgr manager.Manager) (*ClusterCacheTracker, error) {
+	trackersLock.Lock()
+	defer trackersLock.Unlock()
+
+	if t, ok := trackers[mgr]; ok {
+		return t, nil
+	}
+
+	t := NewClusterCacheTracker(mgr.GetClient())
+	if err := mgr.Add(t); err != nil {
+		return nil, err
+	}
+	trackers[mgr] = t
+	return t, nil
+}
+
+// NewClusterCacheTracker creates a new ClusterCacheTracker reading the kubeconfig secrets with c.
+// Start must be called for the connections to be health checked.
+func NewClusterCacheTracker(c client.Client) *ClusterCacheTracker {
+	return &ClusterCacheTracker{
+		client:   c,
+		clusters: map[types.NamespacedName]*trackedCluster{},
+	}
+}
+
+// CoreV1 returns a CoreV1 client for the workload cluster.
+func (t *ClusterCacheTracker) CoreV1(cluster *v1alpha1.Cluster) (corev1.CoreV1Interface, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	tc, err := t.get(cluster)
+	if err != nil {
+		return nil, err
+	}
+	return tc.coreV1, nil
+}
+
+// Client returns a client for the workload cluster using the client-go scheme.
+func (t *ClusterCacheTracker) Client(cluster *v1alpha1.Cluster) (client.Client, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	tc, err := t.get(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	if tc.client == nil {
+		c, err := client.New(tc.restConfig, client.Options{Scheme: scheme.Scheme})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create client for Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+		}
+		tc.client = c
+	}
+	return tc.client, nil
+}
+
+// Nodes returns the Nodes of the workload cluster, indexed by NodeProviderIDIndex.
+// The informer watching them is started the first time they are requested.
+func (t *ClusterCacheTracker) Nodes(cluster *v1alpha1.Cluster) (cache.Indexer, error) {
+	tc, err := t.getNodes(cluster)
+	if err != nil {
+		return nil, err
+	}
+	return tc.nodes.GetIndexer(), nil
+}
+
+// WatchNodes adds handler to the Node informer of the workload cluster, unless a handler
+// with the same name was already added. Handlers are dropped with the connection, so
+// WatchNodes should be called every time the Nodes are used.
+func (t *ClusterCacheTracker) WatchNodes(cluster *v1alpha1.Cluster, name string, handler cache.ResourceEventHandler) error {
+	tc, err := t.getNodes(cluster)
+	if err != nil {
+		return err
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if !tc.handlers[name] {
+		tc.nodes.AddEventHandler(handler)
+		tc.handlers[name] = true
+	}
+	return nil
+}
+
+// Remove tears down the connection to the workload cluster.
+func (t *ClusterCacheTracker) Remove(key types.NamespacedName) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.remove(key)
+}
+
+// Start implements manager.Runnable. It health checks the connections to the
+// workload clusters until stop is closed, then tears them all down.
+func (t *ClusterCacheTracker) Start(stop <-chan struct{}) error {
+	wait.Until(t.checkHealth, HealthCheckInterval, stop)
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for key := range t.clusters {
+		t.remove(key)
+	}
+	return nil
+}
+
+// get returns the connection to the workload cluster, creating it if needed. t.lock must be held.
+func (t *ClusterCacheTracker) get(cluster *v1alpha1.Cluster) (*trackedCluster, error) {
+	key := clusterKey(cluster)
+	if tc, ok := t.clusters[key]; ok {
+		return tc, nil
+	}
+
+	clusterClient, err := NewClusterClient(t.client, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	coreV1, err := clusterClient.CoreV1()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create CoreV1 client for Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+
+	healthConfig := restclient.CopyConfig(clusterClient.RESTConfig())
+	healthConfig.Timeout = HealthCheckTimeout
+	// Don't trace the health checks.
+	healthConfig.WrapTransport = nil
+	health, err := corev1.NewForConfig(healthConfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create health check client for Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+
+	tc := &trackedCluster{
+		restConfig: clusterClient.RESTConfig(),
+		coreV1:     coreV1,
+		health:     health.RESTClient(),
+		handlers:   map[string]bool{},
+		stop:       make(chan struct{}),
+	}
+	t.clusters[key] = tc
+	log.Info("Connected to workload cluster", "cluster", cluster.Name, "namespace", cluster.Namespace)
+	return tc, nil
+}
+
+// getNodes returns the connection to the workload cluster once its Node informer is synced,
+// starting the informer if needed. t.lock must not be held, as syncing the informer can take
+// up to nodeInformerSyncTimeout and must not block the other clusters.
+func (t *ClusterCacheTracker) getNodes(cluster *v1alpha1.Cluster) (*trackedCluster, error) {
+	t.lock.Lock()
+	tc, err := t.get(cluster)
+	if err != nil {
+		t.lock.Unlock()
+		return nil, err
+	}
+	if tc.nodes == nil {
+		tc.nodes = newNodeInformer(tc.coreV1.Nodes())
+		go tc.nodes.Run(tc.stop)
+	}
+	t.lock.Unlock()
+
+	if err := wait.PollImmediate(100*time.Millisecond, nodeInformerSyncTimeout, func() (bool, error) {
+		select {
+		case <-tc.stop:
+			return false, errors.New("connection closed")
+		default:
+			return tc.nodes.HasSynced(), nil
+		}
+	}); err != nil {
+		// The informer can't be restarted, drop the whole connection.
+		t.lock.Lock()
+		if t.clusters[clusterKey(cluster)] == tc {
+			t.remove(clusterKey(cluster))
+		}
+		t.lock.Unlock()
+		return nil, errors.Wrapf(err, "failed to sync Nodes of Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+	return tc, nil
+}
+
+// newNodeInformer returns an informer of the Nodes, indexed by NodeProviderIDIndex.
+func newNodeInformer(nodes corev1.NodeInterface) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return nodes.List(options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return nodes.Watch(options)
+			},
+		},
+		&apicorev1.Node{},
+		0,
+		cache.Indexers{NodeProviderIDIndex: IndexNodeByProviderID},
+	)
+}
+
+// remove tears down the connection to the workload cluster. t.lock must be held.
+func (t *ClusterCacheTracker) remove(key types.NamespacedName) {
+	if tc, ok := t.clusters[key]; ok {
+		log.Info("Disconnected from workload cluster", "cluster", key.Name, "namespace", key.Namespace)
+		close(tc.stop)
+		delete(t.clusters, key)
+	}
+}
+
+// checkHealth tears down the connections to the workload clusters whose Cluster is gone,
+// then probes the API server of the others, and tears down the connections failing
+// HealthCheckFailureThreshold checks in a row.
+func (t *ClusterCacheTracker) checkHealth() {
+	t.lock.Lock()
+	clusters := make(map[types.NamespacedName]*trackedCluster, len(t.clusters))
+	for key, tc := range t.clusters {
+		clusters[key] = tc
+	}
+	t.lock.Unlock()
+
+	for key, tc := range clusters {
+		deleted := apierrors.IsNotFound(t.client.Get(context.Background(), key, &v1alpha1.Cluster{}))
+		var err error
+		if !deleted {
+			err = tc.health.Get().AbsPath("/healthz").Do().Error()
+		}
+
+		t.lock.Lock()
+		// Skip the connections removed while the check was running.
+		if t.clusters[key] == tc {
+			if deleted {
+				t.remove(key)
+			} else if err == nil {
+				tc.failures = 0
+			} else {
+				tc.failures++
+				clusterHealthCheckFailures.WithLabelValues(key.Namespace, key.Name).Inc()
+				log.Error(err, "Workload cluster failed health check", "cluster", key.Name, "namespace", key.Namespace, "failures", tc.failures)
+				if tc.failures >= HealthCheckFailureThreshold {
+					t.remove(key)
+				}
+			}
+		}
+		t.lock.Unlock()
+	}
+}
+
+// IndexNodeByProviderID is a cache.IndexFunc indexing Nodes by the ID of their ProviderID.
+func IndexNodeByProviderID(o interface{}) ([]string, error) {
+	node, ok := o.(*apicorev1.Node)
+	if !ok {
+		return nil, nil
+	}
+
+	providerID, err := noderefutil.NewProviderID(node.Spec.ProviderID)
+	if err != nil {
+		return nil, nil
+	}
+	return []string{providerID.ID()}, nil
+}
+
+func clusterKey(cluster *v1alpha1.Cluster) types.NamespacedName {
+	return types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
+}
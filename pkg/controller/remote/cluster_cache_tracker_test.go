@@ -0,0 +1,189 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newTestTrackedCluster returns a tracker of clusterWithValidKubeConfig pointing at an API server
+// whose health endpoint fails while healthy is 0, and which never answers Node requests.
+func newTestTrackedCluster() (*ClusterCacheTracker, *int32, func()) {
+	healthy := int32(1)
+	hang := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/nodes" {
+			<-hang
+			return
+		}
+		if r.URL.Path != "/healthz" || atomic.LoadInt32(&healthy) == 0 {
+			http.Error(w, "unhealthy", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test1-kubeconfig",
+			Namespace: "test",
+		},
+		Data: map[string][]byte{
+			KubeConfigSecretKey: []byte(fmt.Sprintf(`
+clusters:
+- cluster:
+    server: %s
+  name: test
+contexts:
+- context:
+    cluster: test
+    user: test
+  name: test
+current-context: test
+kind: Config
+users:
+- name: test
+`, server.URL)),
+		},
+	}
+
+	v1alpha1.AddToScheme(scheme.Scheme)
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, secret, clusterWithValidKubeConfig.DeepCopy())
+	return NewClusterCacheTracker(c), &healthy, func() {
+		close(hang)
+		server.Close()
+	}
+}
+
+func TestClusterCacheTrackerCoreV1(t *testing.T) {
+	tracker, _, stop := newTestTrackedCluster()
+	defer stop()
+
+	first, err := tracker.CoreV1(clusterWithValidKubeConfig)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	second, err := tracker.CoreV1(clusterWithValidKubeConfig)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if first != second {
+		t.Fatal("Expected the client of the cluster to be reused")
+	}
+
+	if _, err := tracker.CoreV1(clusterWithNoKubeConfig); err == nil {
+		t.Fatal("Expected error for cluster without kubeconfig, got nil")
+	}
+}
+
+func TestClusterCacheTrackerHealthCheck(t *testing.T) {
+	tracker, healthy, stop := newTestTrackedCluster()
+	defer stop()
+
+	first, err := tracker.CoreV1(clusterWithValidKubeConfig)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	key := clusterKey(clusterWithValidKubeConfig)
+
+	tracker.checkHealth()
+	if tracker.clusters[key] == nil || tracker.clusters[key].failures != 0 {
+		t.Fatal("Expected healthy cluster to be tracked without failures")
+	}
+
+	atomic.StoreInt32(healthy, 0)
+	for i := 1; i < HealthCheckFailureThreshold; i++ {
+		tracker.checkHealth()
+		if tracker.clusters[key] == nil || tracker.clusters[key].failures != i {
+			t.Fatalf("Expected cluster to be tracked with %d failures", i)
+		}
+	}
+
+	tracker.checkHealth()
+	if _, ok := tracker.clusters[key]; ok {
+		t.Fatalf("Expected cluster to be removed after %d failures", HealthCheckFailureThreshold)
+	}
+
+	second, err := tracker.CoreV1(clusterWithValidKubeConfig)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if first == second {
+		t.Fatal("Expected the client of the cluster to be recreated")
+	}
+}
+
+func TestClusterCacheTrackerRemovesDeletedCluster(t *testing.T) {
+	tracker, _, stop := newTestTrackedCluster()
+	defer stop()
+
+	if _, err := tracker.CoreV1(clusterWithValidKubeConfig); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := tracker.client.Delete(context.Background(), clusterWithValidKubeConfig.DeepCopy()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tracker.checkHealth()
+	if _, ok := tracker.clusters[clusterKey(clusterWithValidKubeConfig)]; ok {
+		t.Fatal("Expected the connection to be removed once the Cluster is deleted")
+	}
+}
+
+func TestClusterCacheTrackerNodesDoNotBlock(t *testing.T) {
+	defer func(timeout time.Duration) { nodeInformerSyncTimeout = timeout }(nodeInformerSyncTimeout)
+	nodeInformerSyncTimeout = 2 * time.Second
+
+	tracker, _, stop := newTestTrackedCluster()
+	defer stop()
+
+	done := make(chan error)
+	go func() {
+		_, err := tracker.Nodes(clusterWithValidKubeConfig)
+		done <- err
+	}()
+
+	// The Nodes never sync, which must not block the other users of the tracker.
+	time.Sleep(100 * time.Millisecond)
+	start := time.Now()
+	if _, err := tracker.CoreV1(clusterWithValidKubeConfig); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected CoreV1 not to wait for the Nodes to sync, took %v", elapsed)
+	}
+
+	if err := <-done; err == nil {
+		t.Fatal("Expected an error for Nodes which never sync, got nil")
+	}
+	if _, ok := tracker.clusters[clusterKey(clusterWithValidKubeConfig)]; ok {
+		t.Fatal("Expected the connection to be removed once the Nodes failed to sync")
+	}
+}
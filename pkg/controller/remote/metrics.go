@@ -0,0 +1,40 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// clusterClientErrors counts the failures to create a client for a remote workload cluster.
+	clusterClientErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "capi_remote_cluster_client_errors_total",
+		Help: "Total number of failures to create a client for a workload cluster.",
+	}, []string{"namespace", "cluster"})
+
+	// clusterHealthCheckFailures counts the failed health checks of the connections to remote workload clusters.
+	clusterHealthCheckFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "capi_remote_cluster_health_check_failures_total",
+		Help: "Total number of failed health checks of the connection to a workload cluster.",
+	}, []string{"namespace", "cluster"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(clusterClientErrors, clusterHealthCheckFailures)
+}
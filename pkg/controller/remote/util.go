@@ -27,7 +27,8 @@ import (
 )
 
 const (
-	kubeconfigSecretKey = "value"
+	// KubeConfigSecretKey is the key under which the kubeconfig is stored in the kubeconfig secret.
+	KubeConfigSecretKey = "value"
 )
 
 var (
@@ -41,6 +42,12 @@ func KubeConfigSecretName(cluster string) string {
 	return fmt.Sprintf("%s-kubeconfig", cluster)
 }
 
+// CASecretName generates the expected name for the secret holding the
+// certificate authority of a remote cluster given the cluster's name.
+func CASecretName(cluster string) string {
+	return fmt.Sprintf("%s-ca", cluster)
+}
+
 // GetKubeConfigSecret retrieves the KubeConfig Secret (if any)
 // from the given cluster name and namespace.
 func GetKubeConfigSecret(c client.Client, cluster, namespace string) (*corev1.Secret, error) {
@@ -62,7 +69,7 @@ func GetKubeConfigSecret(c client.Client, cluster, namespace string) (*corev1.Se
 
 // KubeConfigFromSecret uses the Secret to retrieve the KubeConfig.
 func KubeConfigFromSecret(secret *corev1.Secret) ([]byte, error) {
-	data, ok := secret.Data[kubeconfigSecretKey]
+	data, ok := secret.Data[KubeConfigSecretKey]
 	if !ok {
 		return nil, ErrSecretMissingValue
 	}
@@ -53,7 +53,7 @@ users:
 			Namespace: "test",
 		},
 		Data: map[string][]byte{
-			kubeconfigSecretKey: []byte(validKubeConfig),
+			KubeConfigSecretKey: []byte(validKubeConfig),
 		},
 	}
 
@@ -63,7 +63,7 @@ users:
 			Namespace: "test",
 		},
 		Data: map[string][]byte{
-			kubeconfigSecretKey: []byte("Not valid!!1"),
+			KubeConfigSecretKey: []byte("Not valid!!1"),
 		},
 	}
 )
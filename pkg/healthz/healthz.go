@@ -0,0 +1,152 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package healthz serves the liveness and readiness endpoints of the managers.
+package healthz
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// LivenessEndpoint is the path the liveness checks are served at.
+	LivenessEndpoint = "/healthz"
+
+	// ReadinessEndpoint is the path the readiness checks are served at.
+	ReadinessEndpoint = "/readyz"
+
+	// dialTimeout is how long to wait for a connection to the checked server.
+	dialTimeout = 5 * time.Second
+)
+
+// Checker reports an error when the component it checks isn't healthy.
+type Checker func(req *http.Request) error
+
+// Ping is a Checker that always succeeds, it reports the process is able to serve requests.
+func Ping(_ *http.Request) error {
+	return nil
+}
+
+// TLSServer returns a Checker succeeding when a TLS handshake can be completed with the server
+// listening on addr. The certificate of the server isn't verified.
+func TLSServer(addr string) Checker {
+	return func(_ *http.Request) error {
+		conn, err := tls.DialWithDialer(&net.Dialer{Timeout: dialTimeout}, "tcp", addr, &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			return errors.Wrapf(err, "failed to connect to %q", addr)
+		}
+		return conn.Close()
+	}
+}
+
+// Handler serves the result of a set of named checks. It answers with 200 when
+// all checks succeed and with 500 when any of them fails.
+type Handler struct {
+	lock   sync.RWMutex
+	checks map[string]Checker
+}
+
+// AddCheck adds a named check to the handler.
+func (h *Handler) AddCheck(name string, check Checker) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.checks == nil {
+		h.checks = map[string]Checker{}
+	}
+	h.checks[name] = check
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h.lock.RLock()
+	names := make([]string, 0, len(h.checks))
+	for name := range h.checks {
+		names = append(names, name)
+	}
+	checks := make(map[string]Checker, len(h.checks))
+	for name, check := range h.checks {
+		checks[name] = check
+	}
+	h.lock.RUnlock()
+	sort.Strings(names)
+
+	failed := false
+	body := &bytes.Buffer{}
+	for _, name := range names {
+		if err := checks[name](req); err != nil {
+			failed = true
+			fmt.Fprintf(body, "[-]%s failed: %v\n", name, err)
+			continue
+		}
+		fmt.Fprintf(body, "[+]%s ok\n", name)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if failed {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(body, "check failed\n")
+	} else {
+		fmt.Fprint(body, "ok\n")
+	}
+	w.Write(body.Bytes())
+}
+
+// ManagerStarted is a manager.Runnable whose Check fails until the Manager it was added to
+// started it, that is until the caches of the Manager are synced. It doesn't need leader
+// election, standby replicas are reported ready too.
+type ManagerStarted struct {
+	started int32
+}
+
+// Start implements manager.Runnable.
+func (m *ManagerStarted) Start(stop <-chan struct{}) error {
+	atomic.StoreInt32(&m.started, 1)
+	<-stop
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (m *ManagerStarted) NeedLeaderElection() bool {
+	return false
+}
+
+// Check is a Checker failing until the Manager is started.
+func (m *ManagerStarted) Check(_ *http.Request) error {
+	if atomic.LoadInt32(&m.started) == 0 {
+		return errors.New("manager not started yet")
+	}
+	return nil
+}
+
+// NewServeMux returns a ServeMux serving the liveness and readiness handlers.
+func NewServeMux(liveness, readiness *Handler) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle(LivenessEndpoint, liveness)
+	mux.Handle(ReadinessEndpoint, readiness)
+	return mux
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthz
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestHandler(t *testing.T) {
+	testcases := []struct {
+		name           string
+		checks         map[string]Checker
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "no checks",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "ok\n",
+		},
+		{
+			name:           "all checks succeed",
+			checks:         map[string]Checker{"ping": Ping, "other": Ping},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[+]other ok\n[+]ping ok\nok\n",
+		},
+		{
+			name: "a check fails",
+			checks: map[string]Checker{
+				"ping":   Ping,
+				"broken": func(_ *http.Request) error { return errors.New("boom") },
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "[-]broken failed: boom\n[+]ping ok\ncheck failed\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := &Handler{}
+			for name, check := range tc.checks {
+				h.AddCheck(name, check)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, ReadinessEndpoint, nil))
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("expected status %d, got %d", tc.expectedStatus, w.Code)
+			}
+			if w.Body.String() != tc.expectedBody {
+				t.Errorf("expected body %q, got %q", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestManagerStarted(t *testing.T) {
+	m := &ManagerStarted{}
+	if err := m.Check(nil); err == nil {
+		t.Fatal("expected check to fail before the manager is started")
+	}
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() { done <- m.Start(stop) }()
+
+	close(stop)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := m.Check(nil); err != nil {
+		t.Fatalf("expected check to succeed once the manager is started, got %v", err)
+	}
+}
+
+func TestTLSServer(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	addr := strings.TrimPrefix(server.URL, "https://")
+
+	if err := TLSServer(addr)(nil); err != nil {
+		t.Errorf("expected check to succeed, got %v", err)
+	}
+
+	server.Close()
+	if err := TLSServer(addr)(nil); err == nil {
+		t.Error("expected check to fail once the server is closed")
+	}
+}
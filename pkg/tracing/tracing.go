@@ -0,0 +1,83 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing instruments the controllers with OpenCensus spans, exported to
+// an OpenCensus agent or to an OpenTelemetry collector with the opencensus receiver.
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"contrib.go.opencensus.io/exporter/ocagent"
+	"github.com/pkg/errors"
+	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/trace"
+	restclient "k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Setup exports the spans of the process to the agent listening at address, sampling
+// the given ratio of the reconciles.
+func Setup(address, serviceName string, sampleRatio float64) error {
+	exporter, err := ocagent.NewExporter(
+		ocagent.WithInsecure(),
+		ocagent.WithAddress(address),
+		ocagent.WithServiceName(serviceName),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create trace exporter for agent %q", address)
+	}
+
+	trace.RegisterExporter(exporter)
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(sampleRatio)})
+	return nil
+}
+
+// StartReconcile starts the span of a reconcile of the object of the request by the controller.
+func StartReconcile(ctx context.Context, controller string, request reconcile.Request) (context.Context, *trace.Span) {
+	ctx, span := trace.StartSpan(ctx, controller+".Reconcile")
+	span.AddAttributes(
+		trace.StringAttribute("namespace", request.Namespace),
+		trace.StringAttribute("name", request.Name),
+	)
+	return ctx, span
+}
+
+// Start starts a span named name, child of the span of ctx if any.
+func Start(ctx context.Context, name string) (context.Context, *trace.Span) {
+	return trace.StartSpan(ctx, name)
+}
+
+// End ends the span, marking it as failed if err isn't nil.
+func End(span *trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: err.Error()})
+	}
+	span.End()
+}
+
+// WrapTransport traces the requests sent with the configuration as children
+// of the span of their context, if any.
+func WrapTransport(config *restclient.Config) {
+	wrap := config.WrapTransport
+	config.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		if wrap != nil {
+			rt = wrap(rt)
+		}
+		return &ochttp.Transport{Base: rt}
+	}
+}
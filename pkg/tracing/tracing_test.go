@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/trace"
+	"k8s.io/apimachinery/pkg/types"
+	restclient "k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type recordingExporter struct {
+	lock  sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (e *recordingExporter) ExportSpan(s *trace.SpanData) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.spans = append(e.spans, s)
+}
+
+func TestSpans(t *testing.T) {
+	exporter := &recordingExporter{}
+	trace.RegisterExporter(exporter)
+	defer trace.UnregisterExporter(exporter)
+
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "foo"}}
+	ctx, span := trace.StartSpan(context.Background(), "test", trace.WithSampler(trace.AlwaysSample()))
+	ctx, reconcileSpan := StartReconcile(ctx, "machine_controller", request)
+	_, childSpan := Start(ctx, "Actuator.Create")
+	End(childSpan, errors.New("boom"))
+	End(reconcileSpan, nil)
+	span.End()
+
+	if len(exporter.spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(exporter.spans))
+	}
+
+	child, parent := exporter.spans[0], exporter.spans[1]
+	if parent.Name != "machine_controller.Reconcile" {
+		t.Errorf("Expected reconcile span to be named machine_controller.Reconcile, got %q", parent.Name)
+	}
+	if parent.Attributes["namespace"] != "default" || parent.Attributes["name"] != "foo" {
+		t.Errorf("Expected reconcile span to have the namespace and name of the request, got %v", parent.Attributes)
+	}
+	if parent.Code != trace.StatusCodeOK {
+		t.Errorf("Expected reconcile span to succeed, got status %d", parent.Code)
+	}
+
+	if child.ParentSpanID != parent.SpanID {
+		t.Errorf("Expected %q to be a child of the reconcile span", child.Name)
+	}
+	if child.Code != trace.StatusCodeUnknown || child.Message != "boom" {
+		t.Errorf("Expected child span to fail with message boom, got status %d %q", child.Code, child.Message)
+	}
+}
+
+func TestWrapTransport(t *testing.T) {
+	base := http.DefaultTransport
+	wrapped := &http.Transport{}
+	config := &restclient.Config{
+		WrapTransport: func(rt http.RoundTripper) http.RoundTripper {
+			if rt != base {
+				t.Errorf("Expected existing wrapper to be called with the base transport")
+			}
+			return wrapped
+		},
+	}
+
+	WrapTransport(config)
+
+	rt, ok := config.WrapTransport(base).(*ochttp.Transport)
+	if !ok {
+		t.Fatalf("Expected transport to be traced, got %T", rt)
+	}
+	if rt.Base != wrapped {
+		t.Error("Expected traced transport to wrap the existing wrapper")
+	}
+}
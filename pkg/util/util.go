@@ -133,7 +133,13 @@ func GetMachineIfExists(c client.Client, namespace, name string) (*clusterv1.Mac
 
 // IsControlPlaneMachine checks machine is a control plane node.
 func IsControlPlaneMachine(machine *clusterv1.Machine) bool {
-	return machine.Spec.Versions.ControlPlane != ""
+	return IsControlPlaneMachineSpec(&machine.Spec)
+}
+
+// IsControlPlaneMachineSpec checks the machines with the given spec, e.g. the template of a
+// MachineSet or MachineDeployment, are control plane nodes.
+func IsControlPlaneMachineSpec(spec *clusterv1.MachineSpec) bool {
+	return spec.Versions.ControlPlane != ""
 }
 
 // IsNodeReady returns true if a node is ready.
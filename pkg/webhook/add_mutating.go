@@ -0,0 +1,46 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/mutate-cluster-k8s-io-v1alpha1-cluster,mutating=true,failurePolicy=fail,groups=cluster.k8s.io,resources=clusters,verbs=create;update,versions=v1alpha1,name=default.cluster.cluster.k8s.io
+// +kubebuilder:webhook:path=/mutate-cluster-k8s-io-v1alpha1-machineset,mutating=true,failurePolicy=fail,groups=cluster.k8s.io,resources=machinesets,verbs=create;update,versions=v1alpha1,name=default.machineset.cluster.k8s.io
+// +kubebuilder:webhook:path=/mutate-cluster-k8s-io-v1alpha1-machinedeployment,mutating=true,failurePolicy=fail,groups=cluster.k8s.io,resources=machinedeployments,verbs=create;update,versions=v1alpha1,name=default.machinedeployment.cluster.k8s.io
+
+var (
+	_ admission.Defaulter = &v1alpha1.Cluster{}
+	_ admission.Defaulter = &v1alpha1.MachineSet{}
+	_ admission.Defaulter = &v1alpha1.MachineDeployment{}
+)
+
+func init() {
+	AddToManagerFuncs = append(AddToManagerFuncs, addMutating)
+}
+
+// addMutating registers the mutating webhooks filling in default field values.
+func addMutating(mgr manager.Manager) error {
+	server := mgr.GetWebhookServer()
+	server.Register("/mutate-cluster-k8s-io-v1alpha1-cluster", admission.DefaultingWebhookFor(&v1alpha1.Cluster{}))
+	server.Register("/mutate-cluster-k8s-io-v1alpha1-machineset", admission.DefaultingWebhookFor(&v1alpha1.MachineSet{}))
+	server.Register("/mutate-cluster-k8s-io-v1alpha1-machinedeployment", admission.DefaultingWebhookFor(&v1alpha1.MachineDeployment{}))
+	return nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/reporters"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/controller/remote"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// ArtifactsPath returns the folder of the artifacts of the run: $ARTIFACTS when set,
+// as it is in Prow jobs, or _artifacts otherwise.
+func ArtifactsPath() string {
+	if path := os.Getenv("ARTIFACTS"); path != "" {
+		return path
+	}
+	return "_artifacts"
+}
+
+// ClusterArtifactsPath returns the folder of the artifacts of the cluster,
+// <artifactsPath>/clusters/<cluster name>.
+func ClusterArtifactsPath(artifactsPath, clusterName string) string {
+	return filepath.Join(artifactsPath, "clusters", clusterName)
+}
+
+// NewJUnitReporter returns a Ginkgo reporter writing the results of the specs of the
+// suite to <artifactsPath>/junit.<suite>.<parallel node>.xml, to be passed to
+// RunSpecsWithDefaultAndCustomReporters.
+func NewJUnitReporter(artifactsPath, suite string) *reporters.JUnitReporter {
+	return reporters.NewJUnitReporter(filepath.Join(artifactsPath, fmt.Sprintf("junit.%s.%d.xml", suite, config.GinkgoConfig.ParallelNode)))
+}
+
+// WriteKubeconfig writes the kubeconfig of the cluster to the kubeconfig file of the
+// artifacts of the cluster.
+func WriteKubeconfig(ctx context.Context, c client.Client, cluster *v1alpha1.Cluster, artifactsPath string) error {
+	secret, err := remote.GetKubeConfigSecret(c, cluster.Name, cluster.Namespace)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get kubeconfig of Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+	kubeconfig, err := remote.KubeConfigFromSecret(secret)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get kubeconfig of Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+
+	path := ClusterArtifactsPath(artifactsPath, cluster.Name)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return errors.Wrapf(err, "failed to create artifacts folder of Cluster %q", cluster.Name)
+	}
+	return ioutil.WriteFile(filepath.Join(path, "kubeconfig"), kubeconfig, 0600)
+}
+
+// DumpResources writes the Cluster API objects of the cluster as YAML, under
+// resources/<kind>/<name>.yaml in the artifacts of the cluster.
+func DumpResources(ctx context.Context, c client.Client, cluster *v1alpha1.Cluster, artifactsPath string) error {
+	path := filepath.Join(ClusterArtifactsPath(artifactsPath, cluster.Name), "resources")
+	inNamespace := client.InNamespace(cluster.Namespace)
+	belongsToCluster := func(labels map[string]string) bool {
+		return labels[v1alpha1.MachineClusterLabelName] == cluster.Name
+	}
+
+	var errs []error
+	dump := func(kind, name string, obj runtime.Object) {
+		if err := dumpResource(filepath.Join(path, kind, name+".yaml"), obj); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to dump %s %q", kind, name))
+		}
+	}
+
+	dump("Cluster", cluster.Name, cluster)
+
+	machines := &v1alpha1.MachineList{}
+	if err := c.List(ctx, machines, inNamespace); err != nil {
+		errs = append(errs, errors.Wrap(err, "failed to list Machines"))
+	}
+	for i := range machines.Items {
+		if belongsToCluster(machines.Items[i].Labels) {
+			dump("Machine", machines.Items[i].Name, &machines.Items[i])
+		}
+	}
+
+	machineSets := &v1alpha1.MachineSetList{}
+	if err := c.List(ctx, machineSets, inNamespace); err != nil {
+		errs = append(errs, errors.Wrap(err, "failed to list MachineSets"))
+	}
+	for i := range machineSets.Items {
+		if belongsToCluster(machineSets.Items[i].Spec.Template.Labels) {
+			dump("MachineSet", machineSets.Items[i].Name, &machineSets.Items[i])
+		}
+	}
+
+	deployments := &v1alpha1.MachineDeploymentList{}
+	if err := c.List(ctx, deployments, inNamespace); err != nil {
+		errs = append(errs, errors.Wrap(err, "failed to list MachineDeployments"))
+	}
+	for i := range deployments.Items {
+		if belongsToCluster(deployments.Items[i].Spec.Template.Labels) {
+			dump("MachineDeployment", deployments.Items[i].Name, &deployments.Items[i])
+		}
+	}
+
+	return kerrors.NewAggregate(errs)
+}
+
+func dumpResource(path string, obj runtime.Object) error {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
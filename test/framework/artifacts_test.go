@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/controller/remote"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestClusterArtifacts(t *testing.T) {
+	RegisterTestingT(t)
+	v1alpha1.AddToScheme(scheme.Scheme)
+
+	artifacts, err := ioutil.TempDir("", "artifacts")
+	Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(artifacts)
+
+	labels := map[string]string{v1alpha1.MachineClusterLabelName: "foo"}
+	cluster := &v1alpha1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+	c := fake.NewFakeClient(
+		cluster,
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: remote.KubeConfigSecretName("foo"), Namespace: "default"},
+			Data:       map[string][]byte{remote.KubeConfigSecretKey: []byte("kind: Config")},
+		},
+		&v1alpha1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "foo-0", Namespace: "default", Labels: labels}},
+		&v1alpha1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "other-0", Namespace: "default"}},
+		&v1alpha1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo-md-0", Namespace: "default"},
+			Spec: v1alpha1.MachineDeploymentSpec{
+				Template: v1alpha1.MachineTemplateSpec{ObjectMeta: v1alpha1.ObjectMeta{Labels: labels}},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	Expect(WriteKubeconfig(ctx, c, cluster, artifacts)).To(Succeed())
+	kubeconfig, err := ioutil.ReadFile(filepath.Join(artifacts, "clusters", "foo", "kubeconfig"))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(string(kubeconfig)).To(Equal("kind: Config"))
+
+	Expect(DumpResources(ctx, c, cluster, artifacts)).To(Succeed())
+	resources := filepath.Join(artifacts, "clusters", "foo", "resources")
+	for _, path := range []string{"Cluster/foo.yaml", "Machine/foo-0.yaml", "MachineDeployment/foo-md-0.yaml"} {
+		data, err := ioutil.ReadFile(filepath.Join(resources, path))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("namespace: default"))
+	}
+	_, err = os.Stat(filepath.Join(resources, "Machine", "other-0.yaml"))
+	Expect(os.IsNotExist(err)).To(BeTrue())
+
+	other := &v1alpha1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}}
+	Expect(WriteKubeconfig(ctx, c, other, artifacts)).NotTo(Succeed())
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ApplyClusterTemplateAndWaitInput is the input of ApplyClusterTemplateAndWait.
+type ApplyClusterTemplateAndWaitInput struct {
+	// Client is the client of the management cluster.
+	Client client.Client
+
+	// Template is the YAML of a single Cluster and of its Machines, MachineDeployments
+	// and any other object they need, like provider specific classes or secrets.
+	Template []byte
+
+	// Namespace is the namespace of the objects of the template not specifying one.
+	Namespace string
+}
+
+// ApplyClusterTemplateAndWaitResult is the result of ApplyClusterTemplateAndWait.
+type ApplyClusterTemplateAndWaitResult struct {
+	Cluster              *v1alpha1.Cluster
+	ControlPlaneMachines []*v1alpha1.Machine
+	MachineDeployments   []*v1alpha1.MachineDeployment
+}
+
+// ApplyClusterTemplateAndWait creates the objects of the template, then waits for the
+// Cluster to be provisioned, for its control plane Machines to get a Node and for its
+// MachineDeployments to have all their replicas available.
+func ApplyClusterTemplateAndWait(ctx context.Context, input ApplyClusterTemplateAndWaitInput) *ApplyClusterTemplateAndWaitResult {
+	cluster := WaitForClusterToProvision(ctx, input.Client, applyClusterTemplate(ctx, input.Client, input.Template, input.Namespace))
+	return &ApplyClusterTemplateAndWaitResult{
+		Cluster:              cluster,
+		ControlPlaneMachines: WaitForControlPlaneToBeReady(ctx, input.Client, cluster),
+		MachineDeployments:   WaitForMachineDeployments(ctx, input.Client, cluster),
+	}
+}
+
+// WaitForClusterToProvision waits for the cluster to have a control plane endpoint,
+// within ClusterIntervals, and returns it.
+func WaitForClusterToProvision(ctx context.Context, c client.Client, cluster *v1alpha1.Cluster) *v1alpha1.Cluster {
+	var provisioned *v1alpha1.Cluster
+	Eventually(func() (err error) {
+		provisioned, err = checkClusterProvisioned(ctx, c, cluster)
+		return err
+	}, ClusterIntervals.args()...).Should(Succeed())
+	return provisioned
+}
+
+// WaitForControlPlaneToBeReady waits for the cluster to have control plane Machines and
+// for all of them to get a Node, within ControlPlaneIntervals, and returns them.
+func WaitForControlPlaneToBeReady(ctx context.Context, c client.Client, cluster *v1alpha1.Cluster) []*v1alpha1.Machine {
+	var controlPlane []*v1alpha1.Machine
+	Eventually(func() (err error) {
+		controlPlane, err = checkControlPlaneReady(ctx, c, cluster)
+		return err
+	}, ControlPlaneIntervals.args()...).Should(Succeed())
+	return controlPlane
+}
+
+// WaitForMachineDeployments waits for the MachineDeployments of the cluster to have all
+// their replicas updated and available, within MachineDeploymentIntervals, and returns them.
+func WaitForMachineDeployments(ctx context.Context, c client.Client, cluster *v1alpha1.Cluster) []*v1alpha1.MachineDeployment {
+	var deployments []*v1alpha1.MachineDeployment
+	Eventually(func() (err error) {
+		deployments, err = checkMachineDeployments(ctx, c, cluster)
+		return err
+	}, MachineDeploymentIntervals.args()...).Should(Succeed())
+	return deployments
+}
+
+// applyClusterTemplate creates the objects of the template and returns its Cluster.
+func applyClusterTemplate(ctx context.Context, c client.Client, template []byte, namespace string) *v1alpha1.Cluster {
+	objs, err := decodeObjects(template)
+	Expect(err).NotTo(HaveOccurred(), "Failed to decode cluster template")
+
+	var cluster *v1alpha1.Cluster
+	for _, obj := range objs {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(namespace)
+		}
+		if obj.GroupVersionKind() == v1alpha1.SchemeGroupVersion.WithKind("Cluster") {
+			Expect(cluster).To(BeNil(), "Cluster template must contain a single Cluster")
+			cluster = &v1alpha1.Cluster{}
+			cluster.Name, cluster.Namespace = obj.GetName(), obj.GetNamespace()
+		}
+
+		err := c.Create(ctx, obj)
+		if !apierrors.IsAlreadyExists(err) {
+			Expect(err).NotTo(HaveOccurred(), "Failed to create %s %q in namespace %q", obj.GetKind(), obj.GetName(), obj.GetNamespace())
+		}
+	}
+	Expect(cluster).NotTo(BeNil(), "Cluster template must contain a Cluster")
+	return cluster
+}
+
+// checkClusterReady returns an error unless the cluster is provisioned, its control plane
+// is ready and its MachineDeployments have all their replicas available.
+func checkClusterReady(ctx context.Context, c client.Client, cluster *v1alpha1.Cluster) error {
+	if _, err := checkClusterProvisioned(ctx, c, cluster); err != nil {
+		return err
+	}
+	if _, err := checkControlPlaneReady(ctx, c, cluster); err != nil {
+		return err
+	}
+	_, err := checkMachineDeployments(ctx, c, cluster)
+	return err
+}
+
+func checkClusterProvisioned(ctx context.Context, c client.Client, cluster *v1alpha1.Cluster) (*v1alpha1.Cluster, error) {
+	key := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
+	provisioned := &v1alpha1.Cluster{}
+	if err := c.Get(ctx, key, provisioned); err != nil {
+		return nil, err
+	}
+	if len(provisioned.Status.APIEndpoints) == 0 {
+		return nil, errors.Errorf("Cluster %q in namespace %q has no API endpoint", key.Name, key.Namespace)
+	}
+	return provisioned, nil
+}
+
+func checkControlPlaneReady(ctx context.Context, c client.Client, cluster *v1alpha1.Cluster) ([]*v1alpha1.Machine, error) {
+	machines := &v1alpha1.MachineList{}
+	if err := c.List(ctx, machines, client.InNamespace(cluster.Namespace),
+		client.MatchingLabels(map[string]string{v1alpha1.MachineClusterLabelName: cluster.Name})); err != nil {
+		return nil, err
+	}
+
+	var controlPlane []*v1alpha1.Machine
+	for i := range machines.Items {
+		m := &machines.Items[i]
+		if !util.IsControlPlaneMachine(m) {
+			continue
+		}
+		if m.Status.NodeRef == nil {
+			return nil, errors.Errorf("control plane Machine %q has no Node", m.Name)
+		}
+		controlPlane = append(controlPlane, m)
+	}
+	if len(controlPlane) == 0 {
+		return nil, errors.Errorf("Cluster %q in namespace %q has no control plane Machine", cluster.Name, cluster.Namespace)
+	}
+	return controlPlane, nil
+}
+
+func checkMachineDeployments(ctx context.Context, c client.Client, cluster *v1alpha1.Cluster) ([]*v1alpha1.MachineDeployment, error) {
+	list := &v1alpha1.MachineDeploymentList{}
+	if err := c.List(ctx, list, client.InNamespace(cluster.Namespace)); err != nil {
+		return nil, err
+	}
+
+	var deployments []*v1alpha1.MachineDeployment
+	for i := range list.Items {
+		d := &list.Items[i]
+		if d.Spec.Template.Labels[v1alpha1.MachineClusterLabelName] != cluster.Name {
+			continue
+		}
+		if err := checkMachineDeploymentAvailable(d); err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, d)
+	}
+	return deployments, nil
+}
+
+// checkMachineDeploymentAvailable returns an error unless all the replicas of the
+// MachineDeployment are updated and available.
+func checkMachineDeploymentAvailable(d *v1alpha1.MachineDeployment) error {
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	if d.Status.ObservedGeneration < d.Generation || d.Status.UpdatedReplicas != replicas || d.Status.AvailableReplicas != replicas {
+		return errors.Errorf("MachineDeployment %q has %d updated and %d available replicas out of %d",
+			d.Name, d.Status.UpdatedReplicas, d.Status.AvailableReplicas, replicas)
+	}
+	return nil
+}
+
+// decodeObjects decodes the objects of a multi-document YAML.
+func decodeObjects(data []byte) ([]*unstructured.Unstructured, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+
+	var objs []*unstructured.Unstructured
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if err == io.EOF {
+				return objs, nil
+			}
+			return nil, errors.Wrap(err, "failed to decode object")
+		}
+		// Skip empty documents.
+		if len(obj.Object) == 0 {
+			continue
+		}
+		objs = append(objs, obj)
+	}
+}
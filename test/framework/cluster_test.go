@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDecodeObjects(t *testing.T) {
+	RegisterTestingT(t)
+
+	objs, err := decodeObjects([]byte(`
+apiVersion: cluster.k8s.io/v1alpha1
+kind: Cluster
+metadata:
+  name: foo
+---
+---
+apiVersion: cluster.k8s.io/v1alpha1
+kind: MachineDeployment
+metadata:
+  name: foo-md-0
+  namespace: bar
+`))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(objs).To(HaveLen(2))
+	Expect(objs[0].GetKind()).To(Equal("Cluster"))
+	Expect(objs[1].GetKind()).To(Equal("MachineDeployment"))
+	Expect(objs[1].GetNamespace()).To(Equal("bar"))
+
+	_, err = decodeObjects([]byte("kind: [Cluster"))
+	Expect(err).To(HaveOccurred())
+}
+
+func TestWaitForReadyCluster(t *testing.T) {
+	RegisterTestingT(t)
+	v1alpha1.AddToScheme(scheme.Scheme)
+
+	replicas := int32(2)
+	labels := map[string]string{v1alpha1.MachineClusterLabelName: "foo"}
+	c := fake.NewFakeClient(
+		&v1alpha1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Status: v1alpha1.ClusterStatus{
+				APIEndpoints: []v1alpha1.APIEndpoint{{Host: "10.0.0.1", Port: 6443}},
+			},
+		},
+		&v1alpha1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo-controlplane-0", Namespace: "default", Labels: labels},
+			Spec:       v1alpha1.MachineSpec{Versions: v1alpha1.MachineVersionInfo{ControlPlane: "1.14.3"}},
+			Status:     v1alpha1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: "foo-controlplane-0"}},
+		},
+		&v1alpha1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo-md-0-abcde", Namespace: "default", Labels: labels},
+		},
+		&v1alpha1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo-md-0", Namespace: "default"},
+			Spec: v1alpha1.MachineDeploymentSpec{
+				Replicas: &replicas,
+				Template: v1alpha1.MachineTemplateSpec{ObjectMeta: v1alpha1.ObjectMeta{Labels: labels}},
+			},
+			Status: v1alpha1.MachineDeploymentStatus{UpdatedReplicas: 2, AvailableReplicas: 2},
+		},
+		&v1alpha1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-md-0", Namespace: "default"},
+			Spec: v1alpha1.MachineDeploymentSpec{
+				Template: v1alpha1.MachineTemplateSpec{ObjectMeta: v1alpha1.ObjectMeta{
+					Labels: map[string]string{v1alpha1.MachineClusterLabelName: "other"},
+				}},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	cluster := WaitForClusterToProvision(ctx, c, &v1alpha1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}})
+	Expect(cluster.Status.APIEndpoints).To(HaveLen(1))
+
+	controlPlane := WaitForControlPlaneToBeReady(ctx, c, cluster)
+	Expect(controlPlane).To(HaveLen(1))
+	Expect(controlPlane[0].Name).To(Equal("foo-controlplane-0"))
+
+	deployments := WaitForMachineDeployments(ctx, c, cluster)
+	Expect(deployments).To(HaveLen(1))
+	Expect(deployments[0].Name).To(Equal("foo-md-0"))
+}
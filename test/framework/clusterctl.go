@@ -0,0 +1,124 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"context"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/clusterdeployer/clusterclient"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/phases"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// InitManagementClusterInput is the input of InitManagementCluster.
+type InitManagementClusterInput struct {
+	// Kubeconfig is the kubeconfig of the cluster to turn into a management cluster,
+	// for instance a kind cluster.
+	Kubeconfig string
+
+	// ProviderComponents is the YAML of the Cluster API and provider components.
+	ProviderComponents string
+}
+
+// InitManagementCluster applies the provider components to the cluster the way
+// clusterctl does, and waits for the Cluster API to be served.
+func InitManagementCluster(input InitManagementClusterInput) {
+	c, err := clusterclient.New(input.Kubeconfig)
+	Expect(err).NotTo(HaveOccurred(), "Failed to create client of the management cluster")
+	defer c.Close()
+
+	Expect(phases.ApplyClusterAPIComponents(c, input.ProviderComponents)).To(Succeed())
+}
+
+// UpgradeManagementClusterInput is the input of UpgradeManagementCluster.
+type UpgradeManagementClusterInput struct {
+	// Kubeconfig is the kubeconfig of the management cluster.
+	Kubeconfig string
+
+	// Client is the client of the management cluster.
+	Client client.Client
+
+	// ProviderComponents is the YAML of the new Cluster API and provider components.
+	ProviderComponents string
+}
+
+// UpgradeManagementCluster applies newer provider components over the ones of the
+// management cluster, and waits for their controllers to be rolled out, within
+// ControlPlaneIntervals.
+func UpgradeManagementCluster(ctx context.Context, input UpgradeManagementClusterInput) {
+	InitManagementCluster(InitManagementClusterInput{
+		Kubeconfig:         input.Kubeconfig,
+		ProviderComponents: input.ProviderComponents,
+	})
+
+	objs, err := decodeObjects([]byte(input.ProviderComponents))
+	Expect(err).NotTo(HaveOccurred(), "Failed to decode provider components")
+
+	for _, obj := range objs {
+		if obj.GroupVersionKind() != appsv1.SchemeGroupVersion.WithKind("StatefulSet") {
+			continue
+		}
+
+		key := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+		Eventually(func() error {
+			s := &appsv1.StatefulSet{}
+			if err := input.Client.Get(ctx, key, s); err != nil {
+				return err
+			}
+
+			replicas := int32(1)
+			if s.Spec.Replicas != nil {
+				replicas = *s.Spec.Replicas
+			}
+			if s.Status.ObservedGeneration < s.Generation || s.Status.UpdatedReplicas != replicas || s.Status.CurrentRevision != s.Status.UpdateRevision {
+				return errors.Errorf("StatefulSet %q in namespace %q is not rolled out", key.Name, key.Namespace)
+			}
+			return nil
+		}, ControlPlaneIntervals.args()...).Should(Succeed())
+	}
+}
+
+// MoveInput is the input of Move.
+type MoveInput struct {
+	// FromKubeconfig is the kubeconfig of the management cluster to move the objects from.
+	FromKubeconfig string
+
+	// ToKubeconfig is the kubeconfig of the cluster to move the objects to.
+	ToKubeconfig string
+
+	// ProviderComponents is the YAML of the Cluster API and provider components,
+	// applied to the target cluster before moving the objects.
+	ProviderComponents string
+}
+
+// Move installs the provider components on the target cluster and moves all the Cluster
+// API objects of the source cluster to it, like the pivot phase of clusterctl.
+func Move(input MoveInput) {
+	from, err := clusterclient.New(input.FromKubeconfig)
+	Expect(err).NotTo(HaveOccurred(), "Failed to create client of the source cluster")
+	defer from.Close()
+
+	to, err := clusterclient.New(input.ToKubeconfig)
+	Expect(err).NotTo(HaveOccurred(), "Failed to create client of the target cluster")
+	defer to.Close()
+
+	Expect(phases.Pivot(from, to, input.ProviderComponents)).To(Succeed())
+}
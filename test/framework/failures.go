@@ -0,0 +1,85 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/controller/remote"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MachineFailureInjector breaks the instance of a Machine the way a provider can, like
+// stopping the instance or removing it from the load balancer of the control plane.
+type MachineFailureInjector interface {
+	// BreakMachine makes the instance of the machine stop working.
+	BreakMachine(ctx context.Context, managementClusterClient client.Client, m *v1alpha1.Machine) error
+}
+
+// CordonMachineNode marks the Node of the machine unschedulable in the workload cluster.
+func CordonMachineNode(ctx context.Context, c client.Client, cluster *v1alpha1.Cluster, m *v1alpha1.Machine) error {
+	nodes, err := workloadNodes(c, cluster, m)
+	if err != nil {
+		return err
+	}
+
+	node, err := nodes.Get(m.Status.NodeRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get Node %q of Machine %q", m.Status.NodeRef.Name, m.Name)
+	}
+	node.Spec.Unschedulable = true
+	if _, err := nodes.Update(node); err != nil {
+		return errors.Wrapf(err, "failed to cordon Node %q of Machine %q", node.Name, m.Name)
+	}
+	return nil
+}
+
+// DeleteMachineNode deletes the Node of the machine from the workload cluster, as if the
+// instance had disappeared.
+func DeleteMachineNode(ctx context.Context, c client.Client, cluster *v1alpha1.Cluster, m *v1alpha1.Machine) error {
+	nodes, err := workloadNodes(c, cluster, m)
+	if err != nil {
+		return err
+	}
+
+	if err := nodes.Delete(m.Status.NodeRef.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete Node %q of Machine %q", m.Status.NodeRef.Name, m.Name)
+	}
+	return nil
+}
+
+// workloadNodes returns the Node client of the workload cluster of the machine.
+func workloadNodes(c client.Client, cluster *v1alpha1.Cluster, m *v1alpha1.Machine) (corev1.NodeInterface, error) {
+	if m.Status.NodeRef == nil {
+		return nil, errors.Errorf("Machine %q has no Node", m.Name)
+	}
+
+	clusterClient, err := remote.NewClusterClient(c, cluster)
+	if err != nil {
+		return nil, err
+	}
+	coreV1, err := clusterClient.CoreV1()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create client of Cluster %q", cluster.Name)
+	}
+	return coreV1.Nodes(), nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/controller/remote"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBreakMachineNode(t *testing.T) {
+	RegisterTestingT(t)
+	v1alpha1.AddToScheme(scheme.Scheme)
+
+	// The workload cluster serves a single Node, foo-0.
+	var node *corev1.Node
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/nodes/foo-0" || node == nil {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			node = &corev1.Node{}
+			Expect(json.NewDecoder(r.Body).Decode(node)).To(Succeed())
+		case http.MethodDelete:
+			node = nil
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Success"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		Expect(json.NewEncoder(w).Encode(node)).To(Succeed())
+	}))
+	defer server.Close()
+
+	c := fake.NewFakeClient(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: remote.KubeConfigSecretName("foo"), Namespace: "default"},
+		Data: map[string][]byte{remote.KubeConfigSecretKey: []byte(fmt.Sprintf(`
+clusters:
+- cluster:
+    server: %s
+  name: foo
+contexts:
+- context:
+    cluster: foo
+    user: foo
+  name: foo
+current-context: foo
+kind: Config
+users:
+- name: foo
+`, server.URL))},
+	})
+
+	ctx := context.Background()
+	cluster := &v1alpha1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+	machine := &v1alpha1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-0", Namespace: "default"},
+		Status:     v1alpha1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: "foo-0"}},
+	}
+	node = &corev1.Node{
+		TypeMeta:   metav1.TypeMeta{Kind: "Node", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-0"},
+	}
+
+	Expect(CordonMachineNode(ctx, c, cluster, machine)).To(Succeed())
+	Expect(node.Spec.Unschedulable).To(BeTrue())
+
+	Expect(DeleteMachineNode(ctx, c, cluster, machine)).To(Succeed())
+	Expect(node).To(BeNil())
+	Expect(DeleteMachineNode(ctx, c, cluster, machine)).To(Succeed())
+
+	Expect(CordonMachineNode(ctx, c, cluster, &v1alpha1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "pending"}})).
+		To(MatchError(`Machine "pending" has no Node`))
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package framework contains the helpers of the Cluster API end to end tests, for
+// providers to reuse in their own. The helpers fail the running test through Gomega,
+// so RegisterFailHandler or RegisterTestingT must be called before using them.
+package framework
+
+import (
+	"time"
+)
+
+// Intervals are the timeout and polling interval of a wait.
+type Intervals struct {
+	Timeout time.Duration
+	Polling time.Duration
+}
+
+// args returns the intervals as the arguments of gomega.Eventually.
+func (i Intervals) args() []interface{} {
+	return []interface{}{i.Timeout, i.Polling}
+}
+
+var (
+	// ClusterIntervals are the intervals waiting for the control plane endpoint of a Cluster.
+	ClusterIntervals = Intervals{Timeout: 10 * time.Minute, Polling: 10 * time.Second}
+
+	// ControlPlaneIntervals are the intervals waiting for the control plane Machines of a
+	// Cluster to get a Node.
+	ControlPlaneIntervals = Intervals{Timeout: 20 * time.Minute, Polling: 10 * time.Second}
+
+	// MachineDeploymentIntervals are the intervals waiting for the MachineDeployments of a
+	// Cluster to have all their replicas available.
+	MachineDeploymentIntervals = Intervals{Timeout: 20 * time.Minute, Polling: 10 * time.Second}
+)
@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ClusterLogCollector collects the logs of the Machines of a provider. Every provider
+// implements it in its own way, for instance by running journalctl over SSH or by
+// reading the serial console of the instance.
+type ClusterLogCollector interface {
+	// CollectMachineLog writes the logs of the machine, like the ones of the kubelet,
+	// of the container runtime and of the bootstrap of the node, under outputPath.
+	CollectMachineLog(ctx context.Context, managementClusterClient client.Client, m *v1alpha1.Machine, outputPath string) error
+}
+
+// CollectClusterLogs collects the logs of every Machine of the cluster with the collector,
+// under <artifactsPath>/clusters/<cluster name>/machines/<machine name>. It carries on
+// when the logs of a Machine can't be collected, and returns the errors of all of them.
+func CollectClusterLogs(ctx context.Context, c client.Client, cluster *v1alpha1.Cluster, collector ClusterLogCollector, artifactsPath string) error {
+	machines := &v1alpha1.MachineList{}
+	if err := c.List(ctx, machines, client.InNamespace(cluster.Namespace),
+		client.MatchingLabels(map[string]string{v1alpha1.MachineClusterLabelName: cluster.Name})); err != nil {
+		return errors.Wrapf(err, "failed to list Machines of Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+
+	var errs []error
+	for i := range machines.Items {
+		m := &machines.Items[i]
+		outputPath := filepath.Join(ClusterArtifactsPath(artifactsPath, cluster.Name), "machines", m.Name)
+		if err := os.MkdirAll(outputPath, 0755); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to create log folder of Machine %q", m.Name))
+			continue
+		}
+		if err := collector.CollectMachineLog(ctx, c, m, outputPath); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to collect logs of Machine %q", m.Name))
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
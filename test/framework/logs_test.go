@@ -0,0 +1,69 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fileLogCollector struct{}
+
+func (fileLogCollector) CollectMachineLog(_ context.Context, _ client.Client, m *v1alpha1.Machine, outputPath string) error {
+	if m.Name == "broken" {
+		return errors.New("unreachable")
+	}
+	return ioutil.WriteFile(filepath.Join(outputPath, "kubelet.log"), []byte(m.Name), 0644)
+}
+
+func TestCollectClusterLogs(t *testing.T) {
+	RegisterTestingT(t)
+	v1alpha1.AddToScheme(scheme.Scheme)
+
+	artifacts, err := ioutil.TempDir("", "artifacts")
+	Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(artifacts)
+
+	labels := map[string]string{v1alpha1.MachineClusterLabelName: "foo"}
+	c := fake.NewFakeClient(
+		&v1alpha1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "healthy", Namespace: "default", Labels: labels}},
+		&v1alpha1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "broken", Namespace: "default", Labels: labels}},
+		&v1alpha1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}},
+	)
+	cluster := &v1alpha1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+
+	err = CollectClusterLogs(context.Background(), c, cluster, fileLogCollector{}, artifacts)
+	Expect(err).To(MatchError(ContainSubstring(`failed to collect logs of Machine "broken"`)))
+
+	log, err := ioutil.ReadFile(filepath.Join(artifacts, "clusters", "foo", "machines", "healthy", "kubelet.log"))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(string(log)).To(Equal("healthy"))
+
+	_, err = os.Stat(filepath.Join(artifacts, "clusters", "foo", "machines", "other"))
+	Expect(os.IsNotExist(err)).To(BeTrue())
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ScaleIntervals are the intervals waiting for the clusters and MachineDeployments of the
+// scale helpers to be ready.
+var ScaleIntervals = Intervals{Timeout: time.Hour, Polling: 10 * time.Second}
+
+// Measurement is the time an operation of a scale test took.
+type Measurement struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+}
+
+// CreateClustersAndMeasureInput is the input of CreateClustersAndMeasure.
+type CreateClustersAndMeasureInput struct {
+	// Client is the client of the management cluster.
+	Client client.Client
+
+	// Template returns the template of the i-th cluster, see ApplyClusterTemplateAndWaitInput.
+	// The names of the clusters of the templates must be different.
+	Template func(i int) []byte
+
+	// Count is the number of clusters to create.
+	Count int
+
+	// Namespace is the namespace of the objects of the templates not specifying one.
+	Namespace string
+}
+
+// CreateClustersAndMeasure creates Count clusters at once, then waits for all of them to be
+// ready, within ScaleIntervals, and returns how long each of them took. A cluster is ready
+// once it is provisioned, its control plane Machines have a Node and its MachineDeployments
+// have all their replicas available.
+func CreateClustersAndMeasure(ctx context.Context, input CreateClustersAndMeasureInput) []Measurement {
+	start := time.Now()
+	pending := map[string]*v1alpha1.Cluster{}
+	for i := 0; i < input.Count; i++ {
+		cluster := applyClusterTemplate(ctx, input.Client, input.Template(i), input.Namespace)
+		pending[cluster.Name] = cluster
+	}
+	Expect(pending).To(HaveLen(input.Count), "Cluster templates must have different cluster names")
+
+	var measurements []Measurement
+	Eventually(func() int {
+		for name, cluster := range pending {
+			if checkClusterReady(ctx, input.Client, cluster) == nil {
+				measurements = append(measurements, Measurement{Name: name, Duration: time.Since(start)})
+				delete(pending, name)
+			}
+		}
+		return len(pending)
+	}, ScaleIntervals.args()...).Should(BeZero(), "Clusters are not ready")
+	return measurements
+}
+
+// ScaleMachineDeploymentAndMeasure scales the MachineDeployment to the given number of
+// replicas, then waits for all of them to be available, within ScaleIntervals, and
+// returns how long it took.
+func ScaleMachineDeploymentAndMeasure(ctx context.Context, c client.Client, d *v1alpha1.MachineDeployment, replicas int32) Measurement {
+	key := types.NamespacedName{Namespace: d.Namespace, Name: d.Name}
+	scaled := &v1alpha1.MachineDeployment{}
+	Expect(c.Get(ctx, key, scaled)).To(Succeed())
+
+	start := time.Now()
+	scaled.Spec.Replicas = &replicas
+	Expect(c.Update(ctx, scaled)).To(Succeed(), "Failed to scale MachineDeployment %q in namespace %q", key.Name, key.Namespace)
+
+	Eventually(func() error {
+		if err := c.Get(ctx, key, scaled); err != nil {
+			return err
+		}
+		return checkMachineDeploymentAvailable(scaled)
+	}, ScaleIntervals.args()...).Should(Succeed())
+	return Measurement{Name: d.Name, Duration: time.Since(start)}
+}
+
+// WriteMeasurements writes the measurements as JSON to <artifactsPath>/measurements/<name>.json.
+func WriteMeasurements(artifactsPath, name string, measurements []Measurement) error {
+	data, err := json.MarshalIndent(measurements, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal measurements")
+	}
+
+	path := filepath.Join(artifactsPath, "measurements")
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return errors.Wrap(err, "failed to create measurements folder")
+	}
+	return ioutil.WriteFile(filepath.Join(path, name+".json"), data, 0644)
+}
+
+// DumpControllerMetrics writes the metrics served by a controller manager at metricsURL,
+// like the reconcile counts and durations of its controllers, to
+// <artifactsPath>/metrics/<name>.txt.
+func DumpControllerMetrics(ctx context.Context, metricsURL, artifactsPath, name string) error {
+	req, err := http.NewRequest(http.MethodGet, metricsURL, nil)
+	if err != nil {
+		return errors.Wrapf(err, "invalid metrics URL %q", metricsURL)
+	}
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrapf(err, "failed to scrape metrics from %q", metricsURL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed to scrape metrics from %q: %s", metricsURL, resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read metrics from %q", metricsURL)
+	}
+
+	path := filepath.Join(artifactsPath, "metrics")
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return errors.Wrap(err, "failed to create metrics folder")
+	}
+	return ioutil.WriteFile(filepath.Join(path, name+".txt"), data, 0644)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestScaleMachineDeploymentAndMeasure(t *testing.T) {
+	RegisterTestingT(t)
+	v1alpha1.AddToScheme(scheme.Scheme)
+
+	defer func(intervals Intervals) { ScaleIntervals = intervals }(ScaleIntervals)
+	ScaleIntervals = Intervals{Timeout: time.Second, Polling: 10 * time.Millisecond}
+
+	replicas := int32(1)
+	d := &v1alpha1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-md-0", Namespace: "default"},
+		Spec:       v1alpha1.MachineDeploymentSpec{Replicas: &replicas},
+		Status:     v1alpha1.MachineDeploymentStatus{UpdatedReplicas: 3, AvailableReplicas: 3},
+	}
+	c := fake.NewFakeClient(d)
+
+	m := ScaleMachineDeploymentAndMeasure(context.Background(), c, d, 3)
+	Expect(m.Name).To(Equal("foo-md-0"))
+
+	scaled := &v1alpha1.MachineDeployment{}
+	Expect(c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "foo-md-0"}, scaled)).To(Succeed())
+	Expect(*scaled.Spec.Replicas).To(Equal(int32(3)))
+}
+
+func TestWriteMeasurements(t *testing.T) {
+	RegisterTestingT(t)
+
+	artifacts, err := ioutil.TempDir("", "artifacts")
+	Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(artifacts)
+
+	measurements := []Measurement{{Name: "foo", Duration: time.Minute}}
+	Expect(WriteMeasurements(artifacts, "create-clusters", measurements)).To(Succeed())
+
+	data, err := ioutil.ReadFile(filepath.Join(artifacts, "measurements", "create-clusters.json"))
+	Expect(err).NotTo(HaveOccurred())
+	var read []Measurement
+	Expect(json.Unmarshal(data, &read)).To(Succeed())
+	Expect(read).To(Equal(measurements))
+}
+
+func TestDumpControllerMetrics(t *testing.T) {
+	RegisterTestingT(t)
+
+	artifacts, err := ioutil.TempDir("", "artifacts")
+	Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(artifacts)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `controller_runtime_reconcile_total{controller="machine_controller",result="success"} 42`)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	Expect(DumpControllerMetrics(ctx, server.URL+"/metrics", artifacts, "manager")).To(Succeed())
+	data, err := ioutil.ReadFile(filepath.Join(artifacts, "metrics", "manager.txt"))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(string(data)).To(ContainSubstring("machine_controller"))
+
+	Expect(DumpControllerMetrics(ctx, server.URL+"/missing", artifacts, "missing")).NotTo(Succeed())
+}